@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize 把文本切分为小写词元，保留重复词元以便统计词频。
+// 字母和数字按连续片段成词；中日韩文字没有空格分词，输出每个单字以及相邻两字的组合，
+// 例如“傲慢与偏见”得到 傲、慢、与、偏、见 以及 傲慢、慢与、与偏、偏见。
+func Tokenize(text string) []string {
+	var tokens []string
+	forEachRun(text, func(run []rune, cjk bool) {
+		if !cjk {
+			tokens = append(tokens, string(run))
+			return
+		}
+		for i := range run {
+			tokens = append(tokens, string(run[i]))
+			if i+1 < len(run) {
+				tokens = append(tokens, string(run[i:i+2]))
+			}
+		}
+	})
+	return tokens
+}
+
+// QueryTerms 返回查询中去重后的检索词元。
+// 中日韩片段只取相邻两字的组合，单字片段取单字，避免“与”这类常见字匹配出大量无关结果。
+func QueryTerms(text string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	forEachRun(text, func(run []rune, cjk bool) {
+		if !cjk || len(run) == 1 {
+			add(string(run))
+			return
+		}
+		for i := 0; i+1 < len(run); i++ {
+			add(string(run[i : i+2]))
+		}
+	})
+	return terms
+}
+
+// Normalize 返回用于短语匹配的小写文本
+func Normalize(text string) string {
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+// forEachRun 把文本按字符类别切分为连续片段，标点和空白作为分隔符丢弃
+func forEachRun(text string, fn func(run []rune, cjk bool)) {
+	var run []rune
+	runCJK := false
+	flush := func() {
+		if len(run) > 0 {
+			fn(run, runCJK)
+			run = nil
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			if !runCJK {
+				flush()
+			}
+			runCJK = true
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if runCJK {
+				flush()
+			}
+			runCJK = false
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+// isCJK 判断字符是否属于中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
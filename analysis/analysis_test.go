@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"傲慢与偏见", []string{"傲", "傲慢", "慢", "慢与", "与", "与偏", "偏", "偏见", "见"}},
+		{"Go 语言", []string{"go", "语", "语言", "言"}},
+		{"Go2编程", []string{"go2", "编", "编程", "程"}},
+		{"书", []string{"书"}},
+		{"Hello, World! hello", []string{"hello", "world", "hello"}},
+		{"三体：黑暗森林", []string{"三", "三体", "体", "黑", "黑暗", "暗", "暗森", "森", "森林", "林"}},
+		{"ノルウェイの森", []string{"ノ", "ノル", "ル", "ルウ", "ウ", "ウェ", "ェ", "ェイ", "イ", "イの", "の", "の森", "森"}},
+		{"  ,.!  ", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"傲慢与偏见", []string{"傲慢", "慢与", "与偏", "偏见"}},
+		{"与", []string{"与"}},
+		{"Go go GO", []string{"go"}},
+		{"偏见 偏见", []string{"偏见"}},
+		{"Go语言 编程", []string{"go", "语言", "编程"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := QueryTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("QueryTerms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  Pride AND Prejudice "); got != "pride and prejudice" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
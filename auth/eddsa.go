@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA 实现 Ed25519 签名，jwt-go v3 本身不支持 EdDSA
+type SigningMethodEdDSA struct{}
+
+// EdDSA 签名算法实例
+var EdDSA = &SigningMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(EdDSA.Alg(), func() jwt.SigningMethod { return EdDSA })
+}
+
+// Alg 返回 alg 头部的取值
+func (m *SigningMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify 使用 ed25519.PublicKey 校验签名
+func (m *SigningMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Sign 使用 ed25519.PrivateKey 签名
+func (m *SigningMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
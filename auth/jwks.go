@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/dgrijalva/jwt-go"
+	"log"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksCacheTTL 远程公钥集合的缓存时间
+	jwksCacheTTL = 10 * time.Minute
+	// jwksMinRefreshInterval 两次拉取之间的最短间隔，防止被伪造的 kid 刷爆 JWKS 地址
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+// JWK 定义 RFC 7517 中的单个公钥，RSA 使用 n、e，Ed25519 使用 crv、x
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS 定义公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// toJWK 把公钥编码为 JWK
+func toJWK(kid string, key verificationKey) (JWK, error) {
+	jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+	switch public := key.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key.public)
+	}
+	return jwk, nil
+}
+
+// fromJWK 把 JWK 解码为公钥，alg 必须与密钥类型一致
+func fromJWK(jwk JWK) (verificationKey, error) {
+	switch {
+	case jwk.Kty == "RSA" && jwk.Alg == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid n of key %q: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid e of key %q: %w", jwk.Kid, err)
+		}
+		public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if public.N.BitLen() < minRSABits {
+			return verificationKey{}, fmt.Errorf("rsa key %q is too short", jwk.Kid)
+		}
+		return verificationKey{method: jwt.SigningMethodRS256, public: public}, nil
+	case jwk.Kty == "OKP" && jwk.Crv == "Ed25519" && jwk.Alg == EdDSA.Alg():
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return verificationKey{}, fmt.Errorf("invalid x of key %q", jwk.Kid)
+		}
+		return verificationKey{method: EdDSA, public: ed25519.PublicKey(x)}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported key %q: kty=%s alg=%s", jwk.Kid, jwk.Kty, jwk.Alg)
+	}
+}
+
+// publicJWKS 返回本服务全部签名密钥的公钥，按 kid 排序
+func publicJWKS() (JWKS, error) {
+	jwks := JWKS{Keys: make([]JWK, 0, len(localKeys))}
+	for kid, key := range localKeys {
+		jwk, err := toJWK(kid, key)
+		if err != nil {
+			return JWKS{}, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks, nil
+}
+
+// JWKSHandler 发布校验访问 token 所需的公钥
+func JWKSHandler(ctx context.Context, c *app.RequestContext) {
+	jwks, err := publicJWKS()
+	if err != nil {
+		log.Printf("failed to encode jwks: %v", err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to encode jwks",
+			"status": 10003,
+		})
+		return
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksCacheTTL.Seconds())))
+	c.JSON(consts.StatusOK, jwks)
+}
+
+// RegisterRoutes 注册 JWKS 路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/.well-known/jwks.json", JWKSHandler)
+}
+
+// remoteKeySet 从 JWKS 地址拉取公钥并缓存。
+// 查询只读缓存，拉取在后台 goroutine 中进行，同一时间最多一次；
+// 缓存过期时继续使用旧的公钥直到拉取完成，拉取失败时也继续使用旧的缓存。
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]verificationKey
+	fetchedAt time.Time
+	// attemptAt 最近一次开始拉取的时间，两次拉取至少间隔 jwksMinRefreshInterval
+	attemptAt time.Time
+	// inflight 正在进行的拉取，拉取结束时关闭
+	inflight chan struct{}
+	// lastErr 最近一次拉取的错误
+	lastErr error
+}
+
+func newRemoteKeySet(url string) *remoteKeySet {
+	return &remoteKeySet{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (s *remoteKeySet) lookup(kid string) (verificationKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	stale := time.Since(s.fetchedAt) >= jwksCacheTTL
+	s.mu.RUnlock()
+
+	if ok {
+		if stale {
+			s.startRefresh()
+		}
+		return key, nil
+	}
+
+	// 未知 kid 可能是对方刚轮换了密钥，等待一次拉取的结果；被限流时直接拒绝
+	done := s.startRefresh()
+	if done == nil {
+		return verificationKey{}, fmt.Errorf("unknown key id %q", kid)
+	}
+	<-done
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if s.lastErr != nil {
+		return verificationKey{}, s.lastErr
+	}
+	return verificationKey{}, fmt.Errorf("unknown key id %q", kid)
+}
+
+// startRefresh 开始一次后台拉取并返回拉取结束时关闭的通道。
+// 已有拉取在进行时返回该次拉取的通道；距上次开始拉取不足 jwksMinRefreshInterval 时返回 nil
+func (s *remoteKeySet) startRefresh() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight != nil {
+		return s.inflight
+	}
+	if time.Since(s.attemptAt) < jwksMinRefreshInterval {
+		return nil
+	}
+	s.attemptAt = time.Now()
+	done := make(chan struct{})
+	s.inflight = done
+
+	go func() {
+		keys, err := s.fetch()
+		s.mu.Lock()
+		if err != nil {
+			log.Printf("failed to refresh jwks from %s: %v", s.url, err)
+		} else {
+			s.keys = keys
+			s.fetchedAt = time.Now()
+		}
+		s.lastErr = err
+		s.inflight = nil
+		s.mu.Unlock()
+		close(done)
+	}()
+	return done
+}
+
+// fetch 拉取 JWKS 并解码其中的公钥，不持有锁
+func (s *remoteKeySet) fetch() (map[string]verificationKey, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var jwks JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+	keys := make(map[string]verificationKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		key, err := fromJWK(jwk)
+		if err != nil {
+			// 不认识的密钥直接跳过，不影响其他密钥
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newJWKSServer 发布一个 kid 为 k1 的 Ed25519 公钥，gate 中存有通道时每次请求等待通道关闭
+func newJWKSServer(t *testing.T) (*httptest.Server, *int32, *atomic.Value) {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := toJWK("k1", verificationKey{method: EdDSA, public: public})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches int32
+	gate := &atomic.Value{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if block, ok := gate.Load().(chan struct{}); ok {
+			<-block
+		}
+		json.NewEncoder(w).Encode(JWKS{Keys: []JWK{jwk}})
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches, gate
+}
+
+func TestRemoteKeySetRateLimitsUnknownKids(t *testing.T) {
+	server, fetches, _ := newJWKSServer(t)
+	s := newRemoteKeySet(server.URL)
+
+	if _, err := s.lookup("k1"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := s.lookup("forged"); err == nil {
+			t.Fatal("lookup of an unknown kid succeeded")
+		}
+	}
+	if got := atomic.LoadInt32(fetches); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+}
+
+func TestRemoteKeySetServesCachedKeysDuringRefresh(t *testing.T) {
+	server, fetches, gate := newJWKSServer(t)
+	s := newRemoteKeySet(server.URL)
+	if _, err := s.lookup("k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	block := make(chan struct{})
+	gate.Store(block)
+	// 让缓存过期，下一次查询在后台拉取
+	s.mu.Lock()
+	s.fetchedAt = time.Now().Add(-jwksCacheTTL)
+	s.attemptAt = s.fetchedAt
+	s.mu.Unlock()
+
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 5; i++ {
+			if _, err := s.lookup("k1"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookup of a cached kid blocked on the refresh")
+	}
+
+	s.mu.RLock()
+	inflight := s.inflight
+	s.mu.RUnlock()
+	close(block)
+	if inflight != nil {
+		<-inflight
+	}
+	if got := atomic.LoadInt32(fetches); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"awesomeProject/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"os"
+)
+
+// minRSABits RSA 签名密钥的最小长度
+const minRSABits = 2048
+
+// signingKey 定义一把签名访问 token 的私钥，id 写入 kid 头部
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+// verificationKey 定义校验签名用的公钥及其对应的算法
+type verificationKey struct {
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// keySet 按 kid 查找校验公钥
+type keySet interface {
+	lookup(kid string) (verificationKey, error)
+}
+
+// localKeySet 由本服务自己的签名密钥构成的公钥集合
+type localKeySet map[string]verificationKey
+
+func (s localKeySet) lookup(kid string) (verificationKey, error) {
+	key, ok := s[kid]
+	if !ok {
+		return verificationKey{}, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// newSigningKey 根据私钥类型选择签名算法：RSA 使用 RS256，Ed25519 使用 EdDSA
+func newSigningKey(id string, private interface{}) (*signingKey, error) {
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("rsa key %s is %d bits, at least %d required", id, key.N.BitLen(), minRSABits)
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodRS256, private: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{id: id, method: EdDSA, private: key}, nil
+	default:
+		return nil, fmt.Errorf("key %s has unsupported type %T, only RSA and Ed25519 are supported", id, private)
+	}
+}
+
+// verificationKey 返回签名密钥对应的公钥
+func (k *signingKey) verificationKey() verificationKey {
+	return verificationKey{method: k.method, public: k.private.Public()}
+}
+
+// loadSigningKey 从 PEM 文件加载私钥，支持 PKCS#8 以及 PKCS#1 格式的 RSA 私钥
+func loadSigningKey(cfg config.SigningKeyConfig) (*signingKey, error) {
+	data, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", cfg.ID, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found in %s", cfg.ID, cfg.PrivateKeyFile)
+	}
+
+	var private interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", cfg.ID, err)
+	}
+	return newSigningKey(cfg.ID, private)
+}
+
+// generateEphemeralKey 生成只存在于内存中的 RSA 密钥，进程重启后签发的 token 全部失效
+func generateEphemeralKey() (*signingKey, error) {
+	private, err := rsa.GenerateKey(rand.Reader, minRSABits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key id: %w", err)
+	}
+	return newSigningKey("ephemeral-"+hex.EncodeToString(b), private)
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"awesomeProject/config"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestKeys 生成一把 PKCS#1 格式的 RSA 私钥和一把 PKCS#8 格式的 Ed25519 私钥，返回对应的配置
+func writeTestKeys(t *testing.T) (rsaKey, edKey config.SigningKeyConfig) {
+	t.Helper()
+	dir := t.TempDir()
+
+	private, err := rsa.GenerateKey(rand.Reader, minRSABits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey = config.SigningKeyConfig{ID: "rsa-1", PrivateKeyFile: filepath.Join(dir, "rsa.pem")}
+	writePEM(t, rsaKey.PrivateKeyFile, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(private))
+
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey = config.SigningKeyConfig{ID: "ed-2", PrivateKeyFile: filepath.Join(dir, "ed.pem")}
+	writePEM(t, edKey.PrivateKeyFile, "PRIVATE KEY", der)
+	return rsaKey, edKey
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func initKeys(t *testing.T, active string, keys ...config.SigningKeyConfig) {
+	t.Helper()
+	cfg := config.Default().JWT
+	cfg.Keys = keys
+	cfg.ActiveKey = active
+	if err := Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// headerOf 解析 token 头部而不校验签名
+func headerOf(t *testing.T, token string) map[string]interface{} {
+	t.Helper()
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Header
+}
+
+func TestKeyRotation(t *testing.T) {
+	rsaKey, edKey := writeTestKeys(t)
+
+	initKeys(t, rsaKey.ID, rsaKey, edKey)
+	old := signTestToken(t, TokenTypeAccess, time.Hour, nil)
+	if header := headerOf(t, old); header["kid"] != rsaKey.ID || header["alg"] != "RS256" {
+		t.Fatalf("token signed with %s has header %v", rsaKey.ID, header)
+	}
+
+	// 切换启用的密钥后，旧密钥签发的 token 仍可通过其 kid 校验
+	initKeys(t, edKey.ID, rsaKey, edKey)
+	rotated := signTestToken(t, TokenTypeAccess, time.Hour, nil)
+	if header := headerOf(t, rotated); header["kid"] != edKey.ID || header["alg"] != EdDSA.Alg() {
+		t.Fatalf("token signed with %s has header %v", edKey.ID, header)
+	}
+	for name, token := range map[string]string{"old": old, "rotated": rotated} {
+		if _, err := Parse(token, TokenTypeAccess); err != nil {
+			t.Errorf("%s token: Parse() error = %v", name, err)
+		}
+	}
+
+	jwks, err := publicJWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != edKey.ID || jwks.Keys[1].Kid != rsaKey.ID {
+		t.Errorf("publicJWKS() = %+v, want both keys sorted by kid", jwks.Keys)
+	}
+
+	// 旧密钥下线后，它签发的 token 的 kid 无法找到
+	initKeys(t, edKey.ID, edKey)
+	if _, err := Parse(old, TokenTypeAccess); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token of removed key: Parse() error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := Parse(rotated, TokenTypeAccess); err != nil {
+		t.Errorf("token of remaining key: Parse() error = %v", err)
+	}
+}
+
+func TestParseRejectsKidWithMismatchedAlg(t *testing.T) {
+	rsaKey, edKey := writeTestKeys(t)
+	initKeys(t, rsaKey.ID, rsaKey, edKey)
+
+	// 用 RSA 私钥签名，却在 kid 中声明 Ed25519 密钥
+	claims, err := NewClaims(TokenTypeAccess, testPrincipal, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = edKey.ID
+	signed, err := token.SignedString(activeKey.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(signed, TokenTypeAccess); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Parse() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	rsaKey, edKey := writeTestKeys(t)
+	initKeys(t, rsaKey.ID, rsaKey, edKey)
+	for kid, key := range localKeys {
+		jwk, err := toJWK(kid, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := fromJWK(jwk)
+		if err != nil {
+			t.Fatalf("fromJWK(%s) error = %v", kid, err)
+		}
+		if decoded.method != key.method {
+			t.Errorf("fromJWK(%s) method = %s, want %s", kid, decoded.method.Alg(), key.method.Alg())
+		}
+	}
+}
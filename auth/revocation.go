@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// ErrTokenRevoked token 已被提前吊销
+var ErrTokenRevoked = errors.New("token revoked")
+
+var DB *gorm.DB
+
+// InitDB 注入共享的数据库连接、加载签名密钥并授予配置中的管理员角色，中间件通过 DB 查询吊销记录
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	if err := Init(cfg.JWT); err != nil {
+		return err
+	}
+	return promoteAdmins(cfg.Auth.Admins)
+}
+
+// RevokeToken 把访问 token 加入黑名单，记录保留到 token 过期为止
+func RevokeToken(tx *gorm.DB, jti string, userID uint, expiresAt time.Time) error {
+	record := models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error
+}
+
+// RevokeUserTokens 把用户的 token 版本加一，此前签发的全部访问 token 随之失效
+func RevokeUserTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// checkRevoked 检查访问 token 是否在黑名单中，或签发于用户最近一次批量吊销之前
+func checkRevoked(claims *Claims) error {
+	var count int64
+	if err := DB.Model(&models.RevokedToken{}).Where("jti = ?", claims.Id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to query revoked tokens: %w", err)
+	}
+	if count > 0 {
+		return ErrTokenRevoked
+	}
+
+	var user models.User
+	if err := DB.Select("id", "token_version").First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenRevoked
+		}
+		return fmt.Errorf("failed to query user: %w", err)
+	}
+	if claims.TokenVersion < user.TokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// purgeExpired 删除已经过期的黑名单记录和刷新 token，过期的刷新 token 无法再使用，不需要保留
+func purgeExpired() (int64, error) {
+	now := time.Now()
+	result := DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	purged := result.RowsAffected
+	result = DB.Where("expires_at < ?", now).Delete(&models.RefreshToken{})
+	return purged + result.RowsAffected, result.Error
+}
+
+// StartRevocationGC 启动后台任务，每隔 interval 清理一次过期的黑名单记录和刷新 token，返回停止函数
+func StartRevocationGC(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n, err := purgeExpired()
+				if err != nil {
+					log.Printf("failed to purge expired tokens: %v", err)
+				} else if n > 0 {
+					log.Printf("purged %d expired token records", n)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
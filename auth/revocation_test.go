@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"awesomeProject/config"
+	"awesomeProject/migration"
+	"awesomeProject/models"
+	"awesomeProject/storage"
+	"errors"
+	"testing"
+	"time"
+)
+
+// initTestDB 初始化内存数据库和签名密钥，并创建用户 bob
+func initTestDB(t *testing.T) models.User {
+	t.Helper()
+	db, err := storage.OpenInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitDB(db, config.Default()); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Username: "bob", Password: "secret123"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func claimsFor(t *testing.T, user models.User) *Claims {
+	t.Helper()
+	claims, err := NewClaims(TokenTypeAccess, Principal{UserID: user.ID, Username: user.Username, TokenVersion: user.TokenVersion}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestCheckRevokedDenylist(t *testing.T) {
+	user := initTestDB(t)
+	claims := claimsFor(t, user)
+	other := claimsFor(t, user)
+
+	if err := checkRevoked(claims); err != nil {
+		t.Fatalf("fresh token: checkRevoked() = %v", err)
+	}
+	if err := RevokeToken(DB, claims.Id, user.ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		t.Fatal(err)
+	}
+	// 重复吊销同一个 token 不报错
+	if err := RevokeToken(DB, claims.Id, user.ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		t.Fatalf("revoking twice: %v", err)
+	}
+	if err := checkRevoked(claims); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("revoked token: checkRevoked() = %v, want ErrTokenRevoked", err)
+	}
+	if err := checkRevoked(other); err != nil {
+		t.Errorf("other token of the same user: checkRevoked() = %v", err)
+	}
+}
+
+func TestCheckRevokedTokenVersion(t *testing.T) {
+	user := initTestDB(t)
+	before := claimsFor(t, user)
+
+	if err := RevokeUserTokens(DB, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkRevoked(before); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("token issued before revocation: checkRevoked() = %v, want ErrTokenRevoked", err)
+	}
+
+	// 吊销之后立即签发的 token 携带新的版本，即使与吊销发生在同一秒也有效
+	if err := DB.First(&user, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := checkRevoked(claimsFor(t, user)); err != nil {
+		t.Errorf("token issued after revocation: checkRevoked() = %v", err)
+	}
+}
+
+func TestCheckRevokedDeletedUser(t *testing.T) {
+	user := initTestDB(t)
+	claims := claimsFor(t, user)
+	if err := DB.Delete(&models.User{}, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := checkRevoked(claims); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("token of deleted user: checkRevoked() = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	user := initTestDB(t)
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	for jti, expiresAt := range map[string]time.Time{"expired": past, "live": future} {
+		if err := RevokeToken(DB, jti, user.ID, expiresAt); err != nil {
+			t.Fatal(err)
+		}
+		if err := DB.Create(&models.RefreshToken{JTI: jti, FamilyID: "f", UserID: user.ID, ExpiresAt: expiresAt}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := purgeExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("purgeExpired() = %d, want 2", n)
+	}
+	var revoked, refresh int64
+	DB.Model(&models.RevokedToken{}).Where("jti = ?", "live").Count(&revoked)
+	DB.Model(&models.RefreshToken{}).Where("jti = ?", "live").Count(&refresh)
+	if revoked != 1 || refresh != 1 {
+		t.Errorf("live records after purge: revoked %d, refresh %d, want 1, 1", revoked, refresh)
+	}
+}
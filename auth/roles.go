@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"awesomeProject/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"log"
+)
+
+// HasRole 判断调用方是否拥有指定角色
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole 中间件要求调用方至少拥有 roles 中的一个角色，需要挂在 JWTAuthorization 之后
+func RequireRole(roles ...string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		principal, ok := PrincipalFrom(c)
+		if !ok {
+			c.AbortWithStatusJSON(consts.StatusUnauthorized, utils.H{
+				"info":   "Unauthorized",
+				"status": 10005,
+			})
+			return
+		}
+		for _, role := range roles {
+			if principal.HasRole(role) {
+				c.Next(ctx)
+				return
+			}
+		}
+		c.AbortWithStatusJSON(consts.StatusForbidden, utils.H{
+			"info":   "Forbidden",
+			"status": 10004,
+		})
+	}
+}
+
+// AssignRole 给用户授予角色，已拥有该角色时不做任何修改
+func AssignRole(tx *gorm.DB, userID uint, roleName string) error {
+	var role models.Role
+	if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+		return fmt.Errorf("failed to find role %s: %w", roleName, err)
+	}
+	if err := tx.Model(&models.User{ID: userID}).Association("Roles").Append(&role); err != nil {
+		return fmt.Errorf("failed to assign role %s to user %d: %w", roleName, userID, err)
+	}
+	return nil
+}
+
+// promoteAdmins 给配置中的用户授予 admin 角色，尚未注册的用户跳过
+func promoteAdmins(usernames []string) error {
+	for _, username := range usernames {
+		var user models.User
+		if err := DB.Where("username = ?", username).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("admin user %s not found, skipping", username)
+				continue
+			}
+			return fmt.Errorf("failed to find admin user %s: %w", username, err)
+		}
+		if err := AssignRole(DB, user.ID, models.RoleAdmin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"awesomeProject/models"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"testing"
+	"time"
+)
+
+func TestRequireRole(t *testing.T) {
+	user := initTestDB(t)
+	h := server.New()
+	h.GET("/admin", JWTAuthorization(), RequireRole(models.RoleAdmin), func(ctx context.Context, c *app.RequestContext) {
+		c.Status(consts.StatusOK)
+	})
+	// 没有挂 JWTAuthorization 时拿不到 Principal
+	h.GET("/unauthenticated", RequireRole(models.RoleAdmin), func(ctx context.Context, c *app.RequestContext) {
+		c.Status(consts.StatusOK)
+	})
+
+	tokenWithRoles := func(roles ...string) string {
+		principal := Principal{UserID: user.ID, Username: user.Username, Roles: roles}
+		claims, err := NewClaims(TokenTypeAccess, principal, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := Sign(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		auth string
+		want int
+	}{
+		{"admin", "/admin", "Bearer " + tokenWithRoles(models.RoleUser, models.RoleAdmin), consts.StatusOK},
+		{"user", "/admin", "Bearer " + tokenWithRoles(models.RoleUser), consts.StatusForbidden},
+		{"no roles", "/admin", "Bearer " + tokenWithRoles(), consts.StatusForbidden},
+		{"no token", "/admin", "", consts.StatusUnauthorized},
+		{"no principal", "/unauthenticated", "Bearer " + tokenWithRoles(models.RoleAdmin), consts.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ut.PerformRequest(h.Engine, consts.MethodGet, tt.url, nil,
+				ut.Header{Key: "Authorization", Value: tt.auth},
+			).Result()
+			if resp.StatusCode() != tt.want {
+				t.Errorf("status %d, want %d", resp.StatusCode(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPromoteAdmins(t *testing.T) {
+	user := initTestDB(t)
+	// 尚未注册的用户跳过；重复授予不报错
+	if err := promoteAdmins([]string{"bob", "nobody", "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	var loaded models.User
+	if err := DB.Preload("Roles").First(&loaded, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Roles) != 1 || loaded.Roles[0].Name != models.RoleAdmin {
+		t.Errorf("roles after promotion = %+v, want only admin", loaded.Roles)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"awesomeProject/config"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"log"
+	"time"
+)
+
+// token 类型，写入 typ 声明
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Claims 定义 token 中携带的声明，Id 即 jti，Subject 为用户名，TokenVersion 为签发时用户的 token 版本
+type Claims struct {
+	jwt.StandardClaims
+	Type         string   `json:"typ"`
+	UserID       uint     `json:"uid"`
+	Roles        []string `json:"roles,omitempty"`
+	TokenVersion uint     `json:"ver,omitempty"`
+}
+
+// ErrInvalidToken token 无法通过校验
+var ErrInvalidToken = errors.New("invalid token")
+
+// activeKey 签名访问 token；localKeys 是本服务全部签名密钥的公钥，通过 JWKS 发布；
+// verifier 校验访问 token，配置了 jwks_url 时从远程拉取公钥，否则使用 localKeys
+var (
+	activeKey *signingKey
+	localKeys localKeySet
+	verifier  keySet
+)
+
+// refreshKey 签名刷新 token。刷新 token 只由本服务签发和校验，继续使用由 secret 派生的 HMAC 密钥
+var refreshKey []byte
+
+// issuer 和 audience 写入并校验 iss、aud 声明
+var issuer, audience string
+
+// Init 从配置中加载签名密钥、issuer 和 audience。
+// 未配置签名密钥时生成临时密钥，仅适用于本地开发。
+func Init(cfg config.JWTConfig) error {
+	var keys []*signingKey
+	for _, keyCfg := range cfg.Keys {
+		key, err := loadSigningKey(keyCfg)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		key, err := generateEphemeralKey()
+		if err != nil {
+			return err
+		}
+		log.Printf("WARNING: no jwt signing keys configured, using ephemeral key %s; tokens will not survive a restart", key.id)
+		keys = append(keys, key)
+	}
+
+	localKeys = make(localKeySet, len(keys))
+	activeKey = keys[0]
+	for _, key := range keys {
+		localKeys[key.id] = key.verificationKey()
+		if key.id == cfg.ActiveKey {
+			activeKey = key
+		}
+	}
+	if cfg.JWKSURL != "" {
+		verifier = newRemoteKeySet(cfg.JWKSURL)
+	} else {
+		verifier = localKeys
+	}
+
+	mac := hmac.New(sha256.New, []byte(cfg.Secret))
+	mac.Write([]byte("refresh-token"))
+	refreshKey = mac.Sum(nil)
+	issuer = cfg.Issuer
+	audience = cfg.Audience
+	return nil
+}
+
+// NewClaims 为用户构造指定类型的声明，并生成随机 jti
+func NewClaims(typ string, principal Principal, ttl time.Duration) (*Claims, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate token id: %w", err)
+	}
+	now := time.Now()
+	return &Claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        hex.EncodeToString(b),
+			Issuer:    issuer,
+			Audience:  audience,
+			Subject:   principal.Username,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+		Type:         typ,
+		UserID:       principal.UserID,
+		Roles:        principal.Roles,
+		TokenVersion: principal.TokenVersion,
+	}, nil
+}
+
+// Sign 按声明的类型签名：访问 token 使用当前启用的私钥并写入 kid，刷新 token 使用 HMAC
+func Sign(claims *Claims) (string, error) {
+	switch claims.Type {
+	case TokenTypeAccess:
+		token := jwt.NewWithClaims(activeKey.method, claims)
+		token.Header["kid"] = activeKey.id
+		return token.SignedString(activeKey.private)
+	case TokenTypeRefresh:
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(refreshKey)
+	default:
+		return "", fmt.Errorf("unknown token type %q", claims.Type)
+	}
+}
+
+// Parse 校验 token 的签名算法、签名、有效期、issuer、audience 和类型，返回其中的声明
+func Parse(tokenString string, typ string) (*Claims, error) {
+	var parser *jwt.Parser
+	var keyFunc jwt.Keyfunc
+	switch typ {
+	case TokenTypeAccess:
+		parser = &jwt.Parser{ValidMethods: []string{jwt.SigningMethodRS256.Alg(), EdDSA.Alg()}}
+		keyFunc = verificationKeyFor
+	case TokenTypeRefresh:
+		parser = &jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+		keyFunc = func(token *jwt.Token) (interface{}, error) { return refreshKey, nil }
+	default:
+		return nil, fmt.Errorf("unknown token type %q", typ)
+	}
+
+	claims := &Claims{}
+	token, err := parser.ParseWithClaims(tokenString, claims, keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	// StandardClaims.Valid 不要求 exp、iss、aud 必须存在，这里逐项强制校验
+	switch {
+	case claims.ExpiresAt == 0:
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	case !claims.VerifyIssuer(issuer, true):
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	case !claims.VerifyAudience(audience, true):
+		return nil, fmt.Errorf("%w: unexpected audience %q", ErrInvalidToken, claims.Audience)
+	case claims.Type != typ:
+		return nil, fmt.Errorf("%w: unexpected token type %q", ErrInvalidToken, claims.Type)
+	case claims.Id == "" || claims.Subject == "" || claims.UserID == 0:
+		return nil, fmt.Errorf("%w: missing jti, sub or uid", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// verificationKeyFor 按 kid 查找公钥，并要求 token 的算法与公钥的算法一致
+func verificationKeyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid header")
+	}
+	key, err := verifier.lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("key %q does not support alg %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
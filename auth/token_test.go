@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"awesomeProject/config"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initTestKeys 使用默认配置初始化，未配置签名密钥时生成临时密钥
+func initTestKeys(t *testing.T) {
+	t.Helper()
+	if err := Init(config.Default().JWT); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var testPrincipal = Principal{UserID: 1, Username: "bob", Roles: []string{"user"}}
+
+func signTestToken(t *testing.T, typ string, ttl time.Duration, edit func(*Claims)) string {
+	t.Helper()
+	claims, err := NewClaims(typ, testPrincipal, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(claims)
+	}
+	token, err := Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// signWithActiveKey 用访问 token 的私钥签名任意 typ 的声明，模拟类型与签名方式不一致的 token
+func signWithActiveKey(t *testing.T, typ string) string {
+	t.Helper()
+	claims, err := NewClaims(typ, testPrincipal, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(activeKey.method, claims)
+	token.Header["kid"] = activeKey.id
+	signed, err := token.SignedString(activeKey.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	initTestKeys(t)
+	for _, typ := range []string{TokenTypeAccess, TokenTypeRefresh} {
+		claims, err := Parse(signTestToken(t, typ, time.Hour, nil), typ)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", typ, err)
+		}
+		if claims.UserID != testPrincipal.UserID || claims.Subject != testPrincipal.Username || claims.Type != typ {
+			t.Errorf("Parse(%s) = %+v", typ, claims)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	initTestKeys(t)
+	access := signTestToken(t, TokenTypeAccess, time.Hour, nil)
+
+	tests := []struct {
+		name  string
+		token string
+		typ   string
+	}{
+		{"access as refresh", access, TokenTypeRefresh},
+		{"refresh as access", signTestToken(t, TokenTypeRefresh, time.Hour, nil), TokenTypeAccess},
+		{"expired", signTestToken(t, TokenTypeAccess, -time.Minute, nil), TokenTypeAccess},
+		{"bad signature", access[:len(access)-4] + strings.Repeat("A", 4), TokenTypeAccess},
+		{"missing exp", signTestToken(t, TokenTypeAccess, time.Hour, func(c *Claims) { c.ExpiresAt = 0 }), TokenTypeAccess},
+		{"wrong issuer", signTestToken(t, TokenTypeAccess, time.Hour, func(c *Claims) { c.Issuer = "someone-else" }), TokenTypeAccess},
+		{"wrong audience", signTestToken(t, TokenTypeAccess, time.Hour, func(c *Claims) { c.Audience = "someone-else" }), TokenTypeAccess},
+		{"refresh typ signed with access key", signWithActiveKey(t, TokenTypeRefresh), TokenTypeAccess},
+		{"missing uid", signTestToken(t, TokenTypeAccess, time.Hour, func(c *Claims) { c.UserID = 0 }), TokenTypeAccess},
+		{"garbage", "not-a-token", TokenTypeAccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.token, tt.typ); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Parse() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestParseRejectsTokenFromOtherKey(t *testing.T) {
+	initTestKeys(t)
+	token := signTestToken(t, TokenTypeAccess, time.Hour, nil)
+	// 重新初始化生成新的临时密钥，旧密钥签发的 token 的 kid 不再存在
+	initTestKeys(t)
+	if _, err := Parse(token, TokenTypeAccess); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Parse() error = %v, want ErrInvalidToken", err)
+	}
+}
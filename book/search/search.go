@@ -1,144 +1,221 @@
-package main
+package search
 
 import (
-	"bytes"
+	"awesomeProject/analysis"
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/pagination"
+	"awesomeProject/product/cart"
+	"awesomeProject/searchindex"
 	"context"
+	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"github.com/dgrijalva/jwt-go"
+	"gorm.io/gorm"
+	"log"
+	"sort"
+	"strconv"
 )
 
-// Product 定义商品结构体
-type Product struct {
-	ProductID     string  `json:"product_id"`
-	Name          string  `json:"name"`
-	Description   string  `json:"description"`
-	CommentNum    int     `json:"comment_num"`
-	Type          string  `json:"type"`
-	Price         float64 `json:"price"`
-	IsAddedCart   bool    `json:"is_addedCart"`
-	Cover         string  `json:"cover"`
-	PublishTime   string  `json:"publish_time"`
-	Link          string  `json:"link"`
-	ProductNumber string  `json:"product_number"`
+// ProductListResponse 定义商品搜索响应结构体
+type ProductListResponse struct {
+	Status int        `json:"status"`
+	Info   string     `json:"info"`
+	Data   SearchData `json:"data"`
 }
 
-// ProductListResponse 定义商品列表响应结构体
-type ProductListResponse struct {
-	Status int    `json:"status"`
-	Info   string `json:"info"`
-	Data   struct {
-		Products []Product `json:"products"`
-	} `json:"data"`
+// SearchData 定义搜索结果，Facets 按类型统计全部命中的商品数量，不受 type 过滤影响
+type SearchData struct {
+	Products []models.Product `json:"products"`
+	Facets   []TypeFacet      `json:"facets"`
+	pagination.Meta
 }
 
-var jwtKey = []byte("your_secret_key")
-
-// 模拟的商品数据
-var mockProducts = []Product{
-	{
-		ProductID:     "1",
-		Name:          "傲慢与偏见",
-		Description:   "一本书",
-		CommentNum:    35,
-		Type:          "book",
-		Price:         9.80,
-		IsAddedCart:   true,
-		Cover:         "http://127.0.0.1/picture_url1",
-		PublishTime:   "1980-11-07",
-		Link:          "http://127.0.0.1/test1",
-		ProductNumber: "",
-	},
-	{
-		ProductID:     "2",
-		Name:          "T-shirt",
-		Description:   "一件短袖",
-		CommentNum:    100,
-		Type:          "clothes",
-		Price:         88.88,
-		IsAddedCart:   false,
-		Cover:         "http://127.0.0.1/picture_url2",
-		PublishTime:   "1980-11-07",
-		Link:          "http://127.0.0.1/test2",
-		ProductNumber: "",
-	},
+// TypeFacet 定义单个商品类型的命中数量
+type TypeFacet struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
 }
 
-// 验证 JWT Token
-func validateToken(tokenString string) bool {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
-	return err == nil && token.Valid
+// 输入联想返回条数的默认值和上限
+const (
+	defaultCompleteLimit = 10
+	maxCompleteLimit     = 20
+)
+
+// Suggestion 定义一条输入联想结果
+type Suggestion struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
 }
 
-// JWTAuthorization 中间件用于验证 JWT Token
-func JWTAuthorization() app.HandlerFunc {
-	return func(ctx context.Context, c *app.RequestContext) {
-		authHeader := c.GetHeader("Authorization")
-		if len(authHeader) < 7 || !bytes.Equal(authHeader[:7], []byte("Bearer ")) {
-			c.JSON(consts.StatusUnauthorized, utils.H{
-				"info":   "Invalid token format",
-				"status": 10005,
-			})
-			return
-		}
-		tokenString := authHeader[7:]
-		if !validateToken(string(tokenString)) {
-			c.JSON(consts.StatusUnauthorized, utils.H{
-				"info":   "Unauthorized",
-				"status": 10005,
-			})
-			return
-		}
-		c.Next(ctx)
+var DB *gorm.DB
+
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// typeFacets 按商品类型统计数量，数量相同时按类型名排序
+func typeFacets(hits []searchindex.Hit) []TypeFacet {
+	counts := make(map[string]int)
+	for _, hit := range hits {
+		counts[hit.Type]++
 	}
+	facets := make([]TypeFacet, 0, len(counts))
+	for typ, count := range counts {
+		facets = append(facets, TypeFacet{Type: typ, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Type < facets[j].Type
+	})
+	return facets
 }
 
-func processProducts(products []Product, hasValidAuth bool) []Product {
-	if !hasValidAuth {
-		for i := range products {
-			products[i].IsAddedCart = false
+// loadProducts 按 ids 的顺序从数据库读取商品，已删除的商品跳过
+func loadProducts(ids []string) ([]models.Product, error) {
+	products := []models.Product{}
+	if len(ids) == 0 {
+		return products, nil
+	}
+	var found []models.Product
+	if err := DB.Where("product_id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Product, len(found))
+	for _, product := range found {
+		byID[product.ProductID] = product
+	}
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			products = append(products, product)
 		}
 	}
-	return products
+	return products, nil
 }
 
-func main() {
-	h := server.New(server.WithHostPorts("127.0.0.1:8007"))
-
-	h.GET("/book/search", JWTAuthorization(), func(ctx context.Context, c *app.RequestContext) {
-		productName := c.Query("product_name")
-		if productName == "" {
-			c.JSON(consts.StatusBadRequest, utils.H{
-				"status": 10001,
-				"info":   "product_name is required",
-			})
-			return
-		}
+// SearchProducts 在商品索引中全文搜索名称、描述和类型，结果按相关度排序。
+// q 为查询词（兼容旧参数 product_name），type 按类型过滤，page、page_size 分页
+func SearchProducts(ctx context.Context, c *app.RequestContext) {
+	q := c.Query("q")
+	if q == "" {
+		q = c.Query("product_name")
+	}
+	if len(analysis.QueryTerms(q)) == 0 {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10001,
+			"info":   "q is required",
+		})
+		return
+	}
+	params, err := pagination.FromRequest(c)
+	if err == nil && params.Cursor != nil {
+		err = pagination.ErrInvalidCursor
+	}
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10001,
+			"info":   err.Error(),
+		})
+		return
+	}
 
-		var filteredProducts []Product
-		for _, product := range mockProducts {
-			if product.Name == productName {
-				filteredProducts = append(filteredProducts, product)
+	hits := searchindex.Search(q)
+	facets := typeFacets(hits)
+	if typ := c.Query("type"); typ != "" {
+		filtered := hits[:0]
+		for _, hit := range hits {
+			if hit.Type == typ {
+				filtered = append(filtered, hit)
 			}
 		}
+		hits = filtered
+	}
 
-		authHeader := c.GetHeader("Authorization")
-		hasValidAuth := len(authHeader) >= 7 && bytes.Equal(authHeader[:7], []byte("Bearer ")) && validateToken(string(authHeader[7:]))
-		processedProducts := processProducts(filteredProducts, hasValidAuth)
+	var ids []string
+	start := (params.Page - 1) * params.PageSize
+	for i := start; i < len(hits) && i < start+params.PageSize; i++ {
+		ids = append(ids, hits[i].ID)
+	}
+	products, err := loadProducts(ids)
+	if err != nil {
+		log.Printf("Failed to load searched products: %v", err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"status": 10002,
+			"info":   "Database query error",
+		})
+		return
+	}
+	if err := cart.MarkAdded(c, products); err != nil {
+		log.Printf("Failed to mark searched products in cart: %v", err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"status": 10002,
+			"info":   "Database query error",
+		})
+		return
+	}
 
-		resp := ProductListResponse{
-			Status: 10000,
-			Info:   "success",
-			Data: struct {
-				Products []Product `json:"products"`
-			}{Products: processedProducts},
-		}
-		c.JSON(consts.StatusOK, resp)
+	c.JSON(consts.StatusOK, ProductListResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: SearchData{
+			Products: products,
+			Facets:   facets,
+			Meta: pagination.Meta{
+				Page:     params.Page,
+				PageSize: params.PageSize,
+				Total:    int64(len(hits)),
+			},
+		},
+	})
+}
+
+// parseLimit 读取联想条数参数 limit，未提供时使用默认值
+func parseLimit(c *app.RequestContext) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return defaultCompleteLimit, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > maxCompleteLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxCompleteLimit)
+	}
+	return n, nil
+}
+
+// Autocomplete 根据正在输入的查询词联想商品名称，最后一个词按前缀匹配
+func Autocomplete(ctx context.Context, c *app.RequestContext) {
+	q := c.Query("q")
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10001,
+			"info":   err.Error(),
+		})
+		return
+	}
+
+	suggestions := []Suggestion{}
+	for _, hit := range searchindex.Complete(q, limit) {
+		suggestions = append(suggestions, Suggestion{ProductID: hit.ID, Name: hit.Name})
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"status": 10000,
+		"info":   "success",
+		"data":   utils.H{"suggestions": suggestions},
 	})
+}
 
-	h.Spin()
+// RegisterRoutes 注册商品搜索路由，搜索不需要登录，登录后返回加购状态
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/book/search", auth.OptionalAuth(), SearchProducts)
+	r.GET("/book/search/autocomplete", Autocomplete)
+	r.GET("/search/suggest", Suggest)
 }
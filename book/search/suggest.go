@@ -0,0 +1,135 @@
+package search
+
+import (
+	"awesomeProject/analysis"
+	"awesomeProject/models"
+	"awesomeProject/searchindex"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"log"
+	"sort"
+	"strings"
+)
+
+// 商品热度的计算权重：下单数量最能反映热度，其次是评论数和加购次数
+const (
+	cartAddWeight = 1
+	orderWeight   = 3
+	commentWeight = 2
+)
+
+// suggestCandidates 参与热度排序的候选商品数
+const suggestCandidates = 50
+
+// productCount 定义按商品分组统计的结果
+type productCount struct {
+	ProductID string
+	N         int64
+}
+
+// popularity 统计商品热度：累计加购次数、未取消或退款的订单中的下单数量和评论数的加权和
+func popularity(ids []string) (map[string]int64, error) {
+	scores := make(map[string]int64, len(ids))
+	if len(ids) == 0 {
+		return scores, nil
+	}
+
+	var ordered []productCount
+	if err := DB.Model(&models.OrderItem{}).
+		Select("order_items.product_id, SUM(order_items.quantity) AS n").
+		Joins("JOIN orders ON orders.order_id = order_items.order_id").
+		Where("order_items.product_id IN ?", ids).
+		Where("orders.status NOT IN ?", []string{models.OrderStatusCancelled, models.OrderStatusRefunded}).
+		Group("order_items.product_id").
+		Scan(&ordered).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range ordered {
+		scores[row.ProductID] += orderWeight * row.N
+	}
+
+	var products []models.Product
+	if err := DB.Select("product_id", "comment_num", "cart_adds").Where("product_id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		scores[product.ProductID] += cartAddWeight*product.CartAdds + commentWeight*int64(product.CommentNum)
+	}
+	return scores, nil
+}
+
+// didYouMean 把查询中不在索引里的词替换为最接近的索引词，没有需要纠正的词时返回空字符串。
+// 最后一个词可能还没输入完，是某个索引词的前缀时不纠正。
+func didYouMean(q string) string {
+	words := strings.Fields(analysis.Normalize(q))
+	changed := false
+	for i, word := range words {
+		// 只纠正单个字母数字词，中日韩文字和带标点的词原样保留
+		if tokens := analysis.Tokenize(word); len(tokens) != 1 || tokens[0] != word {
+			continue
+		}
+		if term, ok := searchindex.Correct(word, i == len(words)-1); ok && term != word {
+			words[i] = term
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// Suggest 返回搜索框的输入建议：按热度排序的商品名称补全，以及拼写错误时的纠正建议。
+// 原查询没有补全结果时使用纠正后的查询补全。
+func Suggest(ctx context.Context, c *app.RequestContext) {
+	q := c.Query("q")
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10001,
+			"info":   err.Error(),
+		})
+		return
+	}
+
+	correction := didYouMean(q)
+	hits := searchindex.Complete(q, suggestCandidates)
+	if len(hits) == 0 && correction != "" {
+		hits = searchindex.Complete(correction, suggestCandidates)
+	}
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	scores, err := popularity(ids)
+	if err != nil {
+		log.Printf("Failed to load product popularity: %v", err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"status": 10002,
+			"info":   "Database query error",
+		})
+		return
+	}
+	// 热度相同时保持相关度顺序
+	sort.SliceStable(hits, func(i, j int) bool {
+		return scores[hits[i].ID] > scores[hits[j].ID]
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	suggestions := []Suggestion{}
+	for _, hit := range hits {
+		suggestions = append(suggestions, Suggestion{ProductID: hit.ID, Name: hit.Name})
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"status": 10000,
+		"info":   "success",
+		"data": utils.H{
+			"suggestions":  suggestions,
+			"did_you_mean": correction,
+		},
+	})
+}
@@ -1,39 +1,23 @@
-package main
+package delet
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
 	"context"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-// Comment 定义评论结构体
-type Comment struct {
-	ProductID uint `gorm:"primaryKey"`
-	PostID    uint `gorm:"primaryKey"`
-	CommentID uint
-	Content string
-}
-
 var DB *gorm.DB
 
-// 初始化数据库连接
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
-	}
-	// 自动迁移表结构
-	err = DB.AutoMigrate(&Comment{})
-	if err != nil {
-		return fmt.Errorf("failed to auto - migrate database: %w", err)
-	}
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
 	return nil
 }
 
@@ -48,16 +32,9 @@ func DeleteCommentHandler(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
-	var productID, postID uint
-	_, err := fmt.Sscanf(productIDStr, "%d", &productID)
-	if err != nil {
-		c.JSON(consts.StatusBadRequest, utils.H{
-			"info":   "invalid product_id format",
-			"status": 400,
-		})
-		return
-	}
-	_, err = fmt.Sscanf(postIDStr, "%d", &postID)
+	productID := productIDStr
+	var postID uint
+	_, err := fmt.Sscanf(postIDStr, "%d", &postID)
 	if err != nil {
 		c.JSON(consts.StatusBadRequest, utils.H{
 			"info":   "invalid post_id format",
@@ -65,14 +42,6 @@ func DeleteCommentHandler(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
-	token := c.Request.Header.Get("Authorization")
-	if token == "" {
-		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Authorization token is required",
-			"status": 401,
-		})
-		return
-	}
 	if DB == nil {
 		c.JSON(consts.StatusInternalServerError, utils.H{
 			"info":   "Database connection is nil",
@@ -80,7 +49,7 @@ func DeleteCommentHandler(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
-	var comment Comment
+	var comment models.Comment
 	result := DB.First(&comment, "product_id =? AND post_id =?", productID, postID)
 	if result.Error != nil {
 		c.JSON(consts.StatusNotFound, utils.H{
@@ -89,9 +58,18 @@ func DeleteCommentHandler(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
+	// 只有评论作者和管理员可以删除评论
+	principal, _ := auth.PrincipalFrom(c)
+	if comment.UserID != principal.UserID && !principal.HasRole(models.RoleAdmin) {
+		c.JSON(consts.StatusForbidden, utils.H{
+			"info":   "not allowed to delete this comment",
+			"status": 403,
+		})
+		return
+	}
 	// 这里可以添加与product_id和post_id相关的其他逻辑，比如删除该评论对应的文章下的一些统计信息等
 	// 先删除评论
-	result = DB.Delete(&Comment{}, "product_id =? AND post_id =?", productID, postID)
+	result = DB.Delete(&models.Comment{}, "product_id =? AND post_id =?", productID, postID)
 	if result.Error != nil {
 		c.JSON(consts.StatusInternalServerError, utils.H{
 			"info":   fmt.Sprintf("failed to delete comment: %v", result.Error),
@@ -105,13 +83,7 @@ func DeleteCommentHandler(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
-func main() {
-	err := InitDB()
-	if err != nil {
-		fmt.Printf("Failed to initialize database: %v", err)
-		return
-	}
-	h := server.New(server.WithHostPorts("127.0.0.1:8014"))
-	h.DELETE("/comment/{comment_id}", DeleteCommentHandler)
-	h.Spin()
+// RegisterRoutes 注册删除评论路由
+func RegisterRoutes(r *server.Hertz) {
+	r.DELETE("/comment/:comment_id", auth.JWTAuthorization(), DeleteCommentHandler)
 }
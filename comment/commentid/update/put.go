@@ -1,25 +1,19 @@
-package main
+package update
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
 	"context"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"log"
 )
 
-// Comment 定义评论结构体
-type Comment struct {
-	ProductID uint `gorm:"primaryKey"`
-	PostID    uint
-	CommentID uint
-	Content   string
-}
-
 // UpdateCommentRequest 定义更新评论的请求结构体
 type UpdateCommentRequest struct {
 	PostID  uint   `json:"post_id"`
@@ -28,19 +22,9 @@ type UpdateCommentRequest struct {
 
 var DB *gorm.DB
 
-// 初始化数据库连接
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
-	}
-	// 自动迁移表结构
-	err = DB.AutoMigrate(&Comment{})
-	if err != nil {
-		return fmt.Errorf("failed to auto - migrate database: %w", err)
-	}
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
 	return nil
 }
 
@@ -65,15 +49,6 @@ func getAndValidateRequestBody(c *app.RequestContext) (uint, string, error) {
 
 // UpdateCommentHandler 更新评论的处理函数
 func UpdateCommentHandler(ctx context.Context, c *app.RequestContext) {
-	token := c.Request.Header.Get("Authorization")
-	if token == "" {
-		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Authorization token is required",
-			"status": 401,
-		})
-		return
-	}
-
 	postID, content, err := getAndValidateRequestBody(c)
 	if err != nil {
 		c.JSON(consts.StatusBadRequest, utils.H{
@@ -90,7 +65,7 @@ func UpdateCommentHandler(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
-	var comment Comment
+	var comment models.Comment
 	result := DB.First(&comment, "post_id =?", postID)
 	if result.Error != nil {
 		c.JSON(consts.StatusNotFound, utils.H{
@@ -99,8 +74,15 @@ func UpdateCommentHandler(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
-	// 将请求中获取的post_id赋值给comment_id
-	comment.CommentID = postID
+	// 只有评论作者可以修改评论内容
+	principal, _ := auth.PrincipalFrom(c)
+	if comment.UserID != principal.UserID {
+		c.JSON(consts.StatusForbidden, utils.H{
+			"info":   "not allowed to update this comment",
+			"status": 403,
+		})
+		return
+	}
 	comment.Content = content
 	result = DB.Save(&comment)
 	if result.Error != nil {
@@ -116,13 +98,7 @@ func UpdateCommentHandler(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
-func main() {
-	err := InitDB()
-	if err != nil {
-		fmt.Printf("Failed to initialize database: %v", err)
-		return
-	}
-	h := server.New(server.WithHostPorts("127.0.0.1:8015"))
-	h.PUT("/comment/{comment_id}", UpdateCommentHandler)
-	h.Spin()
+// RegisterRoutes 注册更新评论路由
+func RegisterRoutes(r *server.Hertz) {
+	r.PUT("/comment/:comment_id", auth.JWTAuthorization(), UpdateCommentHandler)
 }
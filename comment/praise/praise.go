@@ -1,25 +1,18 @@
-package main
+package praise
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
 	"context"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-// Comment 定义评论结构体
-type Comment struct {
-	ProductID   uint `gorm:"primaryKey"`
-	PostID      uint
-	CommentID   uint
-	Content     string
-	PraiseCount uint
-}
-
 // PraiseRequest 点赞点踩请求结构体
 type PraiseRequest struct {
 	Model     int  `form:"model"`
@@ -28,33 +21,14 @@ type PraiseRequest struct {
 
 var DB *gorm.DB
 
-// 初始化数据库连接
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
-	}
-	// 自动迁移表结构
-	err = DB.AutoMigrate(&Comment{})
-	if err != nil {
-		return fmt.Errorf("failed to auto - migrate database: %w", err)
-	}
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
 	return nil
 }
 
 // PraiseCommentHandler 点赞点踩评论处理函数
 func PraiseCommentHandler(ctx context.Context, c *app.RequestContext) {
-	token := c.Request.Header.Get("Authorization")
-	if token == "" {
-		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Authorization token is required",
-			"status": 401,
-		})
-		return
-	}
-
 	var req PraiseRequest
 	err := c.Bind(&req)
 	if err != nil {
@@ -89,8 +63,8 @@ func PraiseCommentHandler(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	var comment Comment
-	result := DB.First(&comment, "user_id =?", req.CommentID)
+	var comment models.Comment
+	result := DB.First(&comment, "post_id = ?", req.CommentID)
 	if result.Error != nil {
 		c.JSON(consts.StatusNotFound, utils.H{
 			"info":   fmt.Sprintf("comment not found: %v", result.Error),
@@ -117,13 +91,7 @@ func PraiseCommentHandler(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
-func main() {
-	err := InitDB()
-	if err != nil {
-		fmt.Printf("Failed to initialize database: %v", err)
-		return
-	}
-	h := server.New(server.WithHostPorts("127.0.0.1:8016"))
-	h.PUT("/comment/praise", PraiseCommentHandler)
-	h.Spin()
+// RegisterRoutes 注册评论点赞路由
+func RegisterRoutes(r *server.Hertz) {
+	r.PUT("/comment/praise", auth.JWTAuthorization(), PraiseCommentHandler)
 }
@@ -0,0 +1,72 @@
+package get
+
+import (
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CommentInfo 定义返回的评论信息，在评论表基础上补充作者信息
+type CommentInfo struct {
+	models.Comment
+	Avatar    string `json:"avatar"`
+	Nickname  string `json:"nickname"`
+	IsPraised int    `json:"is_praised"`
+}
+
+// CommentResponse 定义获取评论的响应结构体
+type CommentResponse struct {
+	Status   int           `json:"status"`
+	Info     string        `json:"info"`
+	Comments []CommentInfo `json:"comments"`
+}
+
+var DB *gorm.DB
+
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// GetComments 获取商品评论列表
+func GetComments(ctx context.Context, c *app.RequestContext) {
+	productID := c.Param("product_id")
+	if productID == "" {
+		productID = c.Query("product_id")
+	}
+	if productID == "" {
+		c.JSON(http.StatusBadRequest, CommentResponse{
+			Status: 10001,
+			Info:   "product_id is required",
+		})
+		return
+	}
+	var comments []CommentInfo
+	result := DB.Model(&models.Comment{}).
+		Select("comments.*, users.username AS nickname").
+		Joins("LEFT JOIN users ON users.id = comments.user_id").
+		Where("comments.product_id = ?", productID).
+		Scan(&comments)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, CommentResponse{
+			Status: 10002,
+			Info:   "Failed to query comments",
+		})
+		return
+	}
+	resp := CommentResponse{
+		Status:   10000,
+		Info:     "success",
+		Comments: comments,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RegisterRoutes 注册获取评论路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/comment/:product_id", GetComments)
+}
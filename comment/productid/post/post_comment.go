@@ -0,0 +1,107 @@
+package post
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"context"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"log"
+)
+
+// CommentRequest 定义请求体结构体
+type CommentRequest struct {
+	ProductID string `json:"product_id"`
+	Content   string `json:"content"`
+}
+
+var DB *gorm.DB
+
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// 从请求体获取并验证参数
+func getAndValidateRequestBody(c *app.RequestContext) (string, string, error) {
+	var req CommentRequest
+	if err := c.BindJSON(&req); err != nil {
+		log.Printf("Error binding JSON: %v", err)
+		return "", "", fmt.Errorf("Invalid request body format")
+	}
+
+	if req.ProductID == "" {
+		req.ProductID = c.Param("product_id")
+	}
+	if req.ProductID == "" {
+		log.Println("product_id in request body is required")
+		return "", "", fmt.Errorf("product_id in request body is required")
+	}
+	if req.Content == "" {
+		log.Println("content in request body is required")
+		return "", "", fmt.Errorf("content in request body is required")
+	}
+
+	return req.ProductID, req.Content, nil
+}
+
+// 创建评论
+func createComment(userID uint, productID string, content string) (models.Comment, error) {
+	comment := models.Comment{
+		ProductID: productID,
+		UserID:    userID,
+		Content:   content,
+	}
+	result := DB.Create(&comment)
+	if result.Error != nil {
+		log.Printf("Failed to create comment: %v", result.Error)
+		return models.Comment{}, result.Error
+	}
+	return comment, nil
+}
+
+// PostComment 发表商品评论
+func PostComment(ctx context.Context, c *app.RequestContext) {
+	productID, content, err := getAndValidateRequestBody(c)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 10001,
+		})
+		return
+	}
+
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 10005,
+		})
+		return
+	}
+	comment, err := createComment(principal.UserID, productID, content)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to create comment",
+			"status": 10002,
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   comment,
+	})
+}
+
+// RegisterRoutes 注册发表评论路由
+func RegisterRoutes(r *server.Hertz) {
+	r.POST("/comment/:product_id", auth.JWTAuthorization(), PostComment)
+}
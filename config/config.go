@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"awesomeProject/money"
+	"gopkg.in/yaml.v3"
+)
+
+// 运行环境
+const (
+	EnvDev     = "dev"
+	EnvStaging = "staging"
+	EnvProd    = "prod"
+)
+
+// 数据库驱动
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// defaultJWTSecret 开发环境使用的默认密钥，staging 和 prod 环境禁止使用
+const defaultJWTSecret = "your_secret_key"
+
+// Config 定义服务的全部配置
+type Config struct {
+	Env      string         `yaml:"env"`
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Search   SearchConfig   `yaml:"search"`
+	Order    OrderConfig    `yaml:"order"`
+}
+
+// ServerConfig 定义 HTTP 服务配置
+type ServerConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+// DatabaseConfig 定义数据库连接配置。
+// Driver 为 sqlite 时 DSN 是数据库文件路径，":memory:" 表示内存数据库。
+type DatabaseConfig struct {
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn"`
+}
+
+// JWTConfig 定义 JWT 签名配置。
+// 访问 token 使用 Keys 中的私钥签名（RS256 或 EdDSA），ActiveKey 指定签发新 token 的密钥，为空时使用第一把；
+// 其余密钥仍用于校验，轮换时先加入新密钥，切换 ActiveKey，等旧 token 过期后再移除旧密钥。
+// JWKSURL 不为空时从该地址拉取校验公钥，否则直接使用 Keys。Secret 只用于签名刷新 token。
+type JWTConfig struct {
+	Secret     string             `yaml:"secret"`
+	Keys       []SigningKeyConfig `yaml:"keys"`
+	ActiveKey  string             `yaml:"active_key"`
+	JWKSURL    string             `yaml:"jwks_url"`
+	Issuer     string             `yaml:"issuer"`
+	Audience   string             `yaml:"audience"`
+	AccessTTL  time.Duration      `yaml:"access_ttl"`
+	RefreshTTL time.Duration      `yaml:"refresh_ttl"`
+}
+
+// SigningKeyConfig 定义一把签名密钥，ID 写入 token 的 kid 头部
+type SigningKeyConfig struct {
+	ID             string `yaml:"id"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+// AuthConfig 定义授权配置，Admins 中的用户在启动时被授予 admin 角色
+type AuthConfig struct {
+	Admins []string `yaml:"admins"`
+}
+
+// SearchConfig 定义商品搜索配置，SnapshotPath 不为空时启动时从快照加载索引、退出时保存快照
+type SearchConfig struct {
+	SnapshotPath string `yaml:"snapshot_path"`
+}
+
+// OrderConfig 定义订单计价配置：商品总额低于 FreeShippingThreshold 时收取 ShippingFee，
+// FreeShippingThreshold 为 0 表示总是收取运费
+type OrderConfig struct {
+	ShippingFee           money.Money `yaml:"shipping_fee"`
+	FreeShippingThreshold money.Money `yaml:"free_shipping_threshold"`
+}
+
+// Default 返回开发环境的默认配置
+func Default() *Config {
+	return &Config{
+		Env: EnvDev,
+		Server: ServerConfig{
+			Addr: "127.0.0.1:8000",
+		},
+		Database: DatabaseConfig{
+			Driver: DriverMySQL,
+			DSN:    "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local",
+		},
+		JWT: JWTConfig{
+			Secret:     defaultJWTSecret,
+			Issuer:     "awesomeProject/user/token",
+			Audience:   "awesomeProject",
+			AccessTTL:  2 * time.Hour,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Order: OrderConfig{
+			ShippingFee:           money.FromCents(1000),
+			FreeShippingThreshold: money.FromCents(9900),
+		},
+	}
+}
+
+// Load 依次应用默认值、配置文件和环境变量，并校验最终配置。
+// path 为空或文件不存在时只使用默认值和环境变量。
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		default:
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+			}
+		}
+	}
+
+	cfg.applyEnv()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv 使用环境变量覆盖配置项
+func (c *Config) applyEnv() {
+	overrides := map[string]*string{
+		"APP_ENV":                  &c.Env,
+		"APP_SERVER_ADDR":          &c.Server.Addr,
+		"APP_DATABASE_DRIVER":      &c.Database.Driver,
+		"APP_DATABASE_DSN":         &c.Database.DSN,
+		"APP_JWT_SECRET":           &c.JWT.Secret,
+		"APP_JWT_ISSUER":           &c.JWT.Issuer,
+		"APP_JWT_AUDIENCE":         &c.JWT.Audience,
+		"APP_JWT_ACTIVE_KEY":       &c.JWT.ActiveKey,
+		"APP_JWT_JWKS_URL":         &c.JWT.JWKSURL,
+		"APP_SEARCH_SNAPSHOT_PATH": &c.Search.SnapshotPath,
+	}
+	for key, field := range overrides {
+		if value, ok := os.LookupEnv(key); ok {
+			*field = value
+		}
+	}
+}
+
+// Validate 校验配置是否完整、合法
+func (c *Config) Validate() error {
+	var errs []string
+
+	switch c.Env {
+	case EnvDev, EnvStaging, EnvProd:
+	default:
+		errs = append(errs, fmt.Sprintf("env must be one of %s, %s, %s", EnvDev, EnvStaging, EnvProd))
+	}
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		errs = append(errs, fmt.Sprintf("server.addr is invalid: %v", err))
+	}
+	switch c.Database.Driver {
+	case DriverMySQL, DriverSQLite:
+	default:
+		errs = append(errs, fmt.Sprintf("database.driver must be %s or %s", DriverMySQL, DriverSQLite))
+	}
+	if c.Database.DSN == "" {
+		errs = append(errs, "database.dsn is required")
+	}
+	if c.JWT.Secret == "" {
+		errs = append(errs, "jwt.secret is required")
+	} else if c.Env != EnvDev && (c.JWT.Secret == defaultJWTSecret || len(c.JWT.Secret) < 32) {
+		errs = append(errs, "jwt.secret must be a non-default value of at least 32 bytes outside dev")
+	}
+	keyIDs := make(map[string]bool, len(c.JWT.Keys))
+	for i, key := range c.JWT.Keys {
+		switch {
+		case key.ID == "":
+			errs = append(errs, fmt.Sprintf("jwt.keys[%d].id is required", i))
+		case keyIDs[key.ID]:
+			errs = append(errs, fmt.Sprintf("jwt.keys[%d].id %q is duplicated", i, key.ID))
+		}
+		if key.PrivateKeyFile == "" {
+			errs = append(errs, fmt.Sprintf("jwt.keys[%d].private_key_file is required", i))
+		}
+		keyIDs[key.ID] = true
+	}
+	if c.JWT.ActiveKey != "" && !keyIDs[c.JWT.ActiveKey] {
+		errs = append(errs, fmt.Sprintf("jwt.active_key %q does not match any jwt.keys id", c.JWT.ActiveKey))
+	}
+	if c.Env != EnvDev && len(c.JWT.Keys) == 0 {
+		errs = append(errs, "jwt.keys is required outside dev")
+	}
+	if c.JWT.Issuer == "" || c.JWT.Audience == "" {
+		errs = append(errs, "jwt.issuer and jwt.audience are required")
+	}
+	if c.JWT.AccessTTL <= 0 {
+		errs = append(errs, "jwt.access_ttl must be positive")
+	}
+	if c.JWT.RefreshTTL <= c.JWT.AccessTTL {
+		errs = append(errs, "jwt.refresh_ttl must be longer than jwt.access_ttl")
+	}
+	if c.Order.ShippingFee < 0 || c.Order.FreeShippingThreshold < 0 {
+		errs = append(errs, "order.shipping_fee and order.free_shipping_threshold must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"unknown env", func(c *Config) { c.Env = "test" }, "env must be one of"},
+		{"addr without port", func(c *Config) { c.Server.Addr = "localhost" }, "server.addr is invalid"},
+		{"unknown driver", func(c *Config) { c.Database.Driver = "postgres" }, "database.driver must be"},
+		{"empty dsn", func(c *Config) { c.Database.DSN = "" }, "database.dsn is required"},
+		{"default secret in prod", func(c *Config) {
+			c.Env = EnvProd
+			c.JWT.Keys = []SigningKeyConfig{{ID: "k1", PrivateKeyFile: "k1.pem"}}
+		}, "jwt.secret must be a non-default value"},
+		{"no keys in staging", func(c *Config) {
+			c.Env = EnvStaging
+			c.JWT.Secret = strings.Repeat("s", 32)
+		}, "jwt.keys is required outside dev"},
+		{"duplicated key id", func(c *Config) {
+			c.JWT.Keys = []SigningKeyConfig{{ID: "k1", PrivateKeyFile: "a.pem"}, {ID: "k1", PrivateKeyFile: "b.pem"}}
+		}, `jwt.keys[1].id "k1" is duplicated`},
+		{"unknown active key", func(c *Config) {
+			c.JWT.Keys = []SigningKeyConfig{{ID: "k1", PrivateKeyFile: "a.pem"}}
+			c.JWT.ActiveKey = "k2"
+		}, `jwt.active_key "k2" does not match`},
+		{"refresh ttl not longer than access ttl", func(c *Config) { c.JWT.RefreshTTL = c.JWT.AccessTTL }, "jwt.refresh_ttl must be longer"},
+		{"negative shipping fee", func(c *Config) { c.Order.ShippingFee = -1 }, "order.shipping_fee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateProd(t *testing.T) {
+	cfg := Default()
+	cfg.Env = EnvProd
+	cfg.JWT.Secret = strings.Repeat("s", 32)
+	cfg.JWT.Keys = []SigningKeyConfig{{ID: "k1", PrivateKeyFile: "k1.pem"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAppliesFileThenEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+server:
+  addr: 0.0.0.0:9000
+database:
+  driver: sqlite
+  dsn: shop.db
+jwt:
+  access_ttl: 1h
+order:
+  shipping_fee: "5.50"
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_DATABASE_DSN", "override.db")
+	t.Setenv("APP_JWT_SECRET", "from-env")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != "0.0.0.0:9000" || cfg.Database.Driver != DriverSQLite || cfg.JWT.AccessTTL != time.Hour {
+		t.Errorf("file values not applied: addr %s, driver %s, access_ttl %v", cfg.Server.Addr, cfg.Database.Driver, cfg.JWT.AccessTTL)
+	}
+	if cfg.Database.DSN != "override.db" || cfg.JWT.Secret != "from-env" {
+		t.Errorf("env overrides not applied: dsn %s, secret %s", cfg.Database.DSN, cfg.JWT.Secret)
+	}
+	if cfg.Order.ShippingFee.Cents() != 550 {
+		t.Errorf("shipping_fee = %v, want 5.50", cfg.Order.ShippingFee)
+	}
+	if cfg.JWT.Issuer != Default().JWT.Issuer {
+		t.Errorf("issuer = %s, want the default", cfg.JWT.Issuer)
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	t.Setenv("APP_SERVER_ADDR", "127.0.0.1:9100")
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != "127.0.0.1:9100" {
+		t.Errorf("addr = %s, want the APP_SERVER_ADDR override", cfg.Server.Addr)
+	}
+}
+
+func TestLoadValidatesEnvOverrides(t *testing.T) {
+	t.Setenv("APP_ENV", EnvProd)
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "jwt.secret") {
+		t.Errorf("Load() with APP_ENV=prod and the default secret = %v, want a jwt.secret error", err)
+	}
+}
+
+func TestLoadRejectsMalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server: ["), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "failed to parse config file") {
+		t.Errorf("Load() = %v, want a parse error", err)
+	}
+}
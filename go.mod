@@ -5,10 +5,11 @@ go 1.19
 require (
 	github.com/cloudwego/hertz v0.9.5
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	golang.org/x/crypto v0.9.0
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/driver/mysql v1.5.7
 	gorm.io/driver/sqlite v1.5.7
 	gorm.io/gorm v1.25.12
-
 )
 
 require (
@@ -1,25 +1,131 @@
 package main
 
 import (
-  "fmt"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"awesomeProject/auth"
+	"awesomeProject/book/search"
+	"awesomeProject/comment/commentid/delet"
+	"awesomeProject/comment/commentid/update"
+	"awesomeProject/comment/praise"
+	"awesomeProject/comment/productid/get"
+	"awesomeProject/comment/productid/post"
+	"awesomeProject/config"
+	"awesomeProject/migration"
+	"awesomeProject/operate/order"
+	"awesomeProject/product/addCart"
+	"awesomeProject/product/admin"
+	"awesomeProject/product/bytype"
+	"awesomeProject/product/cart"
+	"awesomeProject/product/info/productid"
+	"awesomeProject/product/list"
+	"awesomeProject/searchindex"
+	"awesomeProject/storage"
+	"awesomeProject/user/info"
+	"awesomeProject/user/info/userid"
+	"awesomeProject/user/password"
+	"awesomeProject/user/register"
+	"awesomeProject/user/token"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"gorm.io/gorm"
 )
 
-//TIP To run your code, right-click the code and select <b>Run</b>. Alternatively, click
-// the <icon src="AllIcons.Actions.Execute"/> icon in the gutter and select the <b>Run</b> menu item from here.
+// gcInterval 清理过期 token 记录和过期游客购物车的间隔
+const gcInterval = 10 * time.Minute
 
-func main() {
-  //TIP Press <shortcut actionId="ShowIntentionActions"/> when your caret is at the underlined or highlighted text
-  // to see how GoLand suggests fixing it.
-  s := "gopher"
-  fmt.Println("Hello and welcome, %s!", s)
-
-  for i := 1; i <= 5; i++ {
-	//TIP You can try debugging your code. We have set one <icon src="AllIcons.Debugger.Db_set_breakpoint"/> breakpoint
-	// for you, but you can always add more by pressing <shortcut actionId="ToggleLineBreakpoint"/>. To start your debugging session, 
-	// right-click your code in the editor and select the <b>Debug</b> option. 
-	fmt.Println("i =", 100/i)
-  }
+// module 描述一个可挂载到网关上的功能模块
+type module struct {
+	name           string
+	setup          func(db *gorm.DB, cfg *config.Config) error
+	registerRoutes func(r *server.Hertz)
+}
+
+// modules 网关挂载的全部功能模块
+var modules = []module{
+	{"auth", auth.InitDB, auth.RegisterRoutes},
+	{"user/register", register.InitDB, register.RegisterRoutes},
+	{"user/token", token.InitDB, token.RegisterRoutes},
+	{"user/info", info.InitDB, info.RegisterRoutes},
+	{"user/info/userid", userid.InitDB, userid.RegisterRoutes},
+	{"user/password", password.InitDB, password.RegisterRoutes},
+	{"searchindex", searchindex.InitDB, func(r *server.Hertz) {}},
+	{"book/search", search.InitDB, search.RegisterRoutes},
+	{"product/list", list.InitDB, list.RegisterRoutes},
+	{"product/bytype", bytype.InitDB, bytype.RegisterRoutes},
+	{"product/info/productid", productid.InitDB, productid.RegisterRoutes},
+	{"product/cart", cart.InitDB, cart.RegisterRoutes},
+	{"product/addCart", addcart.InitDB, addcart.RegisterRoutes},
+	{"product/admin", admin.InitDB, admin.RegisterRoutes},
+	{"comment/productid/get", get.InitDB, get.RegisterRoutes},
+	{"comment/productid/post", post.InitDB, post.RegisterRoutes},
+	{"comment/commentid/update", update.InitDB, update.RegisterRoutes},
+	{"comment/commentid/delet", delet.InitDB, delet.RegisterRoutes},
+	{"comment/praise", praise.InitDB, praise.RegisterRoutes},
+	{"operate/order", order.InitDB, order.RegisterRoutes},
 }
 
-//TIP See GoLand help at <a href="https://www.jetbrains.com/help/go/">jetbrains.com/help/go/</a>.
-// Also, you can try interactive lessons for GoLand by selecting 'Help | Learn IDE Features' from the main menu.
\ No newline at end of file
+func main() {
+	defaultPath := os.Getenv("APP_CONFIG")
+	if defaultPath == "" {
+		defaultPath = "config.yaml"
+	}
+	configPath := flag.String("config", defaultPath, "path to the YAML config file")
+	migrateCmd := flag.String("migrate", "", `run migrations and exit: "up" applies pending migrations, "down" rolls back the latest one`)
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := storage.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+
+	switch *migrateCmd {
+	case "":
+		if err := migration.Up(db); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	case "up":
+		if err := migration.Up(db); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+		return
+	case "down":
+		if err := migration.Down(db, 1); err != nil {
+			log.Fatalf("failed to roll back database: %v", err)
+		}
+		return
+	default:
+		log.Fatalf("unknown -migrate command %q", *migrateCmd)
+	}
+
+	for _, m := range modules {
+		if err := m.setup(db, cfg); err != nil {
+			log.Fatalf("failed to initialize %s: %v", m.name, err)
+		}
+	}
+	stopRevocationGC := auth.StartRevocationGC(gcInterval)
+	stopGuestCartGC := cart.StartGuestCartGC(gcInterval)
+
+	h := server.New(server.WithHostPorts(cfg.Server.Addr))
+	for _, m := range modules {
+		m.registerRoutes(h)
+	}
+	h.OnShutdown = append(h.OnShutdown,
+		func(ctx context.Context) { stopRevocationGC() },
+		func(ctx context.Context) { stopGuestCartGC() },
+		func(ctx context.Context) {
+			if err := searchindex.SaveSnapshot(); err != nil {
+				log.Printf("%v", err)
+			}
+		},
+	)
+	h.Spin()
+}
@@ -0,0 +1,86 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Password  string `gorm:"type:varchar(255);not null"`
+	Email     string `gorm:"type:varchar(255)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (userV1) TableName() string { return "users" }
+
+type productV1 struct {
+	ProductID   string  `gorm:"primaryKey;type:varchar(64)"`
+	Name        string  `gorm:"type:varchar(255);not null"`
+	Description string  `gorm:"type:text"`
+	Type        string  `gorm:"type:varchar(64);index"`
+	CommentNum  int     `gorm:"not null;default:0"`
+	Price       float64 `gorm:"not null;default:0"`
+	IsAddedCart bool    `gorm:"not null;default:false"`
+	Cover       string  `gorm:"type:varchar(255)"`
+	PublishTime string  `gorm:"type:varchar(32)"`
+	Link        string  `gorm:"type:varchar(255)"`
+	Num         int     `gorm:"not null;default:0"`
+}
+
+func (productV1) TableName() string { return "products" }
+
+type cartV1 struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index"`
+	ProductID string `gorm:"type:varchar(64);not null;index"`
+}
+
+func (cartV1) TableName() string { return "carts" }
+
+type orderV1 struct {
+	OrderID   uint    `gorm:"primaryKey"`
+	UserID    uint    `gorm:"not null;index"`
+	Address   string  `gorm:"type:varchar(255);not null"`
+	Total     float64 `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (orderV1) TableName() string { return "orders" }
+
+type orderItemV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	OrderID   uint   `gorm:"not null;index"`
+	ProductID string `gorm:"type:varchar(64);not null"`
+	Quantity  uint   `gorm:"not null"`
+}
+
+func (orderItemV1) TableName() string { return "order_items" }
+
+type commentV1 struct {
+	PostID      uint      `gorm:"primaryKey"`
+	ProductID   string    `gorm:"type:varchar(64);not null;index"`
+	UserID      uint      `gorm:"not null;index"`
+	Content     string    `gorm:"type:text;not null"`
+	PraiseCount int       `gorm:"not null;default:0"`
+	PublishTime time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time
+}
+
+func (commentV1) TableName() string { return "comments" }
+
+// initialSchema 建立 users、products、carts、orders、order_items、comments 六张表。
+// 使用 AutoMigrate 而不是 CreateTable，以便接管各服务此前各自创建的旧表。
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&userV1{}, &productV1{}, &cartV1{}, &orderV1{}, &orderItemV1{}, &commentV1{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&commentV1{}, &orderItemV1{}, &orderV1{}, &cartV1{}, &productV1{}, &userV1{})
+	},
+}
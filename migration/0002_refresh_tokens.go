@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type refreshTokenV2 struct {
+	ID        uint      `gorm:"primaryKey"`
+	JTI       string    `gorm:"column:jti;type:varchar(64);uniqueIndex;not null"`
+	FamilyID  string    `gorm:"type:varchar(64);index;not null"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (refreshTokenV2) TableName() string { return "refresh_tokens" }
+
+// refreshTokens 新增 refresh_tokens 表，用于刷新 token 的轮换和吊销
+var refreshTokens = Migration{
+	Version: 2,
+	Name:    "refresh_tokens",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&refreshTokenV2{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&refreshTokenV2{})
+	},
+}
@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type revokedTokenV3 struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+func (revokedTokenV3) TableName() string { return "revoked_tokens" }
+
+type userV3 struct {
+	TokensRevokedAt *time.Time
+}
+
+func (userV3) TableName() string { return "users" }
+
+// tokenRevocation 新增访问 token 黑名单表，并为用户增加批量吊销的时间点
+var tokenRevocation = Migration{
+	Version: 3,
+	Name:    "token_revocation",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&revokedTokenV3{}); err != nil {
+			return err
+		}
+		return tx.Migrator().AddColumn(&userV3{}, "TokensRevokedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropColumn(&userV3{}, "TokensRevokedAt"); err != nil {
+			return err
+		}
+		// 补建用户名唯一索引
+		if err := restoreIndexes(tx, &userV1{}); err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable(&revokedTokenV3{})
+	},
+}
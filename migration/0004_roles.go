@@ -0,0 +1,40 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type roleV4 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(32);uniqueIndex;not null"`
+	CreatedAt time.Time
+}
+
+func (roleV4) TableName() string { return "roles" }
+
+type userRoleV4 struct {
+	UserID uint `gorm:"primaryKey"`
+	RoleID uint `gorm:"primaryKey;index"`
+}
+
+func (userRoleV4) TableName() string { return "user_roles" }
+
+// roles 新增角色表和用户角色关联表，预置 admin、user 两个角色，并给已有用户授予 user 角色
+var roles = Migration{
+	Version: 4,
+	Name:    "roles",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&roleV4{}, &userRoleV4{}); err != nil {
+			return err
+		}
+		if err := tx.Create(&[]roleV4{{Name: "admin"}, {Name: "user"}}).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO user_roles (user_id, role_id) SELECT users.id, roles.id FROM users, roles WHERE roles.name = ?", "user").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&userRoleV4{}, &roleV4{})
+	},
+}
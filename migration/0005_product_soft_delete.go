@@ -0,0 +1,31 @@
+package migration
+
+import "gorm.io/gorm"
+
+type productV5 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (productV5) TableName() string { return "products" }
+
+// productSoftDelete 给商品增加 deleted_at，商品下架改为软删除
+var productSoftDelete = Migration{
+	Version: 5,
+	Name:    "product_soft_delete",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&productV5{}, "DeletedAt"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&productV5{}, "DeletedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&productV5{}, "DeletedAt"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropColumn(&productV5{}, "DeletedAt"); err != nil {
+			return err
+		}
+		// 补建商品类型索引
+		return restoreIndexes(tx, &productV1{})
+	},
+}
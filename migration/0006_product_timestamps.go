@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type productV6 struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time `gorm:"index"`
+}
+
+func (productV6) TableName() string { return "products" }
+
+// productTimestamps 给商品增加创建和更新时间，已有商品以迁移时间填充
+var productTimestamps = Migration{
+	Version: 6,
+	Name:    "product_timestamps",
+	Up: func(tx *gorm.DB) error {
+		for _, field := range []string{"CreatedAt", "UpdatedAt"} {
+			if err := tx.Migrator().AddColumn(&productV6{}, field); err != nil {
+				return err
+			}
+		}
+		if err := tx.Migrator().CreateIndex(&productV6{}, "UpdatedAt"); err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Exec("UPDATE products SET created_at = ?, updated_at = ?", now, now).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&productV6{}, "UpdatedAt"); err != nil {
+			return err
+		}
+		for _, field := range []string{"UpdatedAt", "CreatedAt"} {
+			if err := tx.Migrator().DropColumn(&productV6{}, field); err != nil {
+				return err
+			}
+		}
+		// 补建商品类型和软删除索引
+		return restoreIndexes(tx, &productV1{}, &productV5{})
+	},
+}
@@ -0,0 +1,25 @@
+package migration
+
+import "gorm.io/gorm"
+
+type productV7 struct {
+	IsAddedCart bool `gorm:"not null;default:false"`
+}
+
+func (productV7) TableName() string { return "products" }
+
+// dropIsAddedCart 删除商品表中的 is_added_cart，加购状态改为按调用方的购物车计算
+var dropIsAddedCart = Migration{
+	Version: 7,
+	Name:    "drop_is_added_cart",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropColumn(&productV7{}, "IsAddedCart"); err != nil {
+			return err
+		}
+		// 补建商品类型、软删除和更新时间索引
+		return restoreIndexes(tx, &productV1{}, &productV5{}, &productV6{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&productV7{}, "IsAddedCart")
+	},
+}
@@ -0,0 +1,69 @@
+package migration
+
+import "gorm.io/gorm"
+
+type cartV8 struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index;uniqueIndex:idx_carts_user_product"`
+	ProductID string `gorm:"type:varchar(64);not null;index;uniqueIndex:idx_carts_user_product"`
+	Quantity  uint   `gorm:"not null;default:1"`
+}
+
+func (cartV8) TableName() string { return "carts" }
+
+// cartGroupV8 同一用户同一商品的购物车记录统计
+type cartGroupV8 struct {
+	UserID    uint
+	ProductID string
+	Live      int64
+}
+
+// cartQuantity 给购物车增加数量，并给 (user_id, product_id) 加唯一索引。
+// 已有的重复记录合并为一行：保留最早的未删除记录，数量为未删除记录的条数，其余记录物理删除。
+// 回滚只删除数量和唯一索引，合并掉的记录无法恢复。
+var cartQuantity = Migration{
+	Version: 8,
+	Name:    "cart_quantity",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&cartV8{}, "Quantity"); err != nil {
+			return err
+		}
+
+		var groups []cartGroupV8
+		if err := tx.Raw(`SELECT user_id, product_id, SUM(CASE WHEN deleted_at IS NULL THEN 1 ELSE 0 END) AS live
+			FROM carts GROUP BY user_id, product_id HAVING COUNT(*) > 1`).Scan(&groups).Error; err != nil {
+			return err
+		}
+		for _, g := range groups {
+			var ids []uint
+			if err := tx.Model(&cartV8{}).Unscoped().
+				Where("user_id = ? AND product_id = ?", g.UserID, g.ProductID).
+				Order("CASE WHEN deleted_at IS NULL THEN 0 ELSE 1 END, id").
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			quantity := g.Live
+			if quantity == 0 {
+				quantity = 1
+			}
+			if err := tx.Model(&cartV8{}).Unscoped().Where("id = ?", ids[0]).Update("quantity", quantity).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("id IN ?", ids[1:]).Delete(&cartV8{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Migrator().CreateIndex(&cartV8{}, "idx_carts_user_product")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&cartV8{}, "idx_carts_user_product"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropColumn(&cartV8{}, "Quantity"); err != nil {
+			return err
+		}
+		// 补建 user_id、product_id 和软删除索引
+		return restoreIndexes(tx, &cartV1{})
+	},
+}
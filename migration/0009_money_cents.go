@@ -0,0 +1,79 @@
+package migration
+
+import "gorm.io/gorm"
+
+type productV9 struct {
+	Price      float64 `gorm:"not null;default:0"`
+	PriceCents int64   `gorm:"not null;default:0"`
+}
+
+func (productV9) TableName() string { return "products" }
+
+type orderV9 struct {
+	Total      float64 `gorm:"not null;default:0"`
+	TotalCents int64   `gorm:"not null;default:0"`
+}
+
+func (orderV9) TableName() string { return "orders" }
+
+// indexesV9 迁移前后各表上的全部索引，删除列之后按表补建
+var indexesV9 = map[string][]interface{}{
+	"products": {&productV1{}, &productV5{}, &productV6{}},
+	"orders":   {&orderV1{}},
+}
+
+// toCentsV9 把浮点金额列换成同名的整数列，以分为单位，四舍五入到分
+func toCentsV9(tx *gorm.DB, model interface{}, table, column string) error {
+	m := tx.Migrator()
+	cents := column + "_cents"
+	if err := m.AddColumn(model, cents); err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE " + table + " SET " + cents + " = ROUND(" + column + " * 100)").Error; err != nil {
+		return err
+	}
+	if err := m.DropColumn(model, column); err != nil {
+		return err
+	}
+	if err := m.RenameColumn(model, cents, column); err != nil {
+		return err
+	}
+	return restoreIndexes(tx, indexesV9[table]...)
+}
+
+// fromCentsV9 是 toCentsV9 的逆操作
+func fromCentsV9(tx *gorm.DB, model interface{}, table, column string) error {
+	m := tx.Migrator()
+	cents := column + "_cents"
+	if err := m.RenameColumn(model, column, cents); err != nil {
+		return err
+	}
+	if err := m.AddColumn(model, column); err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE " + table + " SET " + column + " = " + cents + " / 100.0").Error; err != nil {
+		return err
+	}
+	if err := m.DropColumn(model, cents); err != nil {
+		return err
+	}
+	return restoreIndexes(tx, indexesV9[table]...)
+}
+
+// moneyCents 把商品价格和订单总额从浮点数改为以分为单位的整数
+var moneyCents = Migration{
+	Version: 9,
+	Name:    "money_cents",
+	Up: func(tx *gorm.DB) error {
+		if err := toCentsV9(tx, &productV9{}, "products", "price"); err != nil {
+			return err
+		}
+		return toCentsV9(tx, &orderV9{}, "orders", "total")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := fromCentsV9(tx, &orderV9{}, "orders", "total"); err != nil {
+			return err
+		}
+		return fromCentsV9(tx, &productV9{}, "products", "price")
+	},
+}
@@ -0,0 +1,40 @@
+package migration
+
+import "gorm.io/gorm"
+
+type userV10 struct {
+	ID uint `gorm:"primaryKey"`
+}
+
+func (userV10) TableName() string { return "users" }
+
+type cartV10 struct {
+	gorm.Model
+	UserID    uint    `gorm:"not null;index;uniqueIndex:idx_carts_user_product"`
+	User      userV10 `gorm:"constraint:OnDelete:CASCADE"`
+	ProductID string  `gorm:"type:varchar(64);not null;index;uniqueIndex:idx_carts_user_product"`
+	Quantity  uint    `gorm:"not null;default:1"`
+}
+
+func (cartV10) TableName() string { return "carts" }
+
+// cartUserForeignKey 给 carts.user_id 加上指向 users 的外键，先删除用户已不存在的购物车记录
+var cartUserForeignKey = Migration{
+	Version: 10,
+	Name:    "cart_user_fk",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM carts WHERE user_id NOT IN (SELECT id FROM users)").Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().CreateConstraint(&cartV10{}, "User"); err != nil {
+			return err
+		}
+		return restoreIndexes(tx, &cartV10{})
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropConstraint(&cartV10{}, "User"); err != nil {
+			return err
+		}
+		return restoreIndexes(tx, &cartV10{})
+	},
+}
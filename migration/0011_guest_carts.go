@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type guestCartItemV11 struct {
+	ID        uint   `gorm:"primaryKey"`
+	GuestID   string `gorm:"type:varchar(64);not null;uniqueIndex:idx_guest_cart_items_guest_product"`
+	ProductID string `gorm:"type:varchar(64);not null;uniqueIndex:idx_guest_cart_items_guest_product"`
+	Quantity  uint   `gorm:"not null;default:1"`
+	CreatedAt time.Time
+	UpdatedAt time.Time `gorm:"index"`
+}
+
+func (guestCartItemV11) TableName() string { return "guest_cart_items" }
+
+// guestCarts 新增游客购物车表
+var guestCarts = Migration{
+	Version: 11,
+	Name:    "guest_carts",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&guestCartItemV11{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&guestCartItemV11{})
+	},
+}
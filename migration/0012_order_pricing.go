@@ -0,0 +1,52 @@
+package migration
+
+import "gorm.io/gorm"
+
+type orderV12 struct {
+	ShippingFee int64 `gorm:"not null;default:0"`
+}
+
+func (orderV12) TableName() string { return "orders" }
+
+type orderItemV12 struct {
+	UnitPrice int64 `gorm:"not null;default:0"`
+	Subtotal  int64 `gorm:"not null;default:0"`
+}
+
+func (orderItemV12) TableName() string { return "order_items" }
+
+// orderPricing 给订单增加运费，给订单项增加单价和小计快照。
+// 已有订单项按迁移时的商品价格补齐，已有订单的运费记为 0
+var orderPricing = Migration{
+	Version: 12,
+	Name:    "order_pricing",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.AddColumn(&orderV12{}, "ShippingFee"); err != nil {
+			return err
+		}
+		for _, field := range []string{"UnitPrice", "Subtotal"} {
+			if err := m.AddColumn(&orderItemV12{}, field); err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(`UPDATE order_items SET unit_price = COALESCE(
+			(SELECT price FROM products WHERE products.product_id = order_items.product_id), 0)`).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE order_items SET subtotal = unit_price * quantity").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range []string{"Subtotal", "UnitPrice"} {
+			if err := m.DropColumn(&orderItemV12{}, field); err != nil {
+				return err
+			}
+		}
+		if err := m.DropColumn(&orderV12{}, "ShippingFee"); err != nil {
+			return err
+		}
+		// 补建订单的用户索引和订单项的订单索引
+		return restoreIndexes(tx, &orderV1{}, &orderItemV1{})
+	},
+}
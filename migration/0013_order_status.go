@@ -0,0 +1,69 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type orderV13 struct {
+	Status      string `gorm:"type:varchar(32);not null;default:pending_payment;index"`
+	PaidAt      *time.Time
+	ShippedAt   *time.Time
+	DeliveredAt *time.Time
+	CompletedAt *time.Time
+	CancelledAt *time.Time
+	RefundedAt  *time.Time
+}
+
+func (orderV13) TableName() string { return "orders" }
+
+type orderEventV13 struct {
+	ID         uint   `gorm:"primaryKey"`
+	OrderID    uint   `gorm:"not null;index"`
+	FromStatus string `gorm:"type:varchar(32);not null;default:''"`
+	ToStatus   string `gorm:"type:varchar(32);not null"`
+	ActorID    uint   `gorm:"not null"`
+	Note       string `gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt  time.Time
+}
+
+func (orderEventV13) TableName() string { return "order_events" }
+
+// orderStatusFields 迁移新增的订单列，按添加顺序排列
+var orderStatusFields = []string{"Status", "PaidAt", "ShippedAt", "DeliveredAt", "CompletedAt", "CancelledAt", "RefundedAt"}
+
+// orderStatus 给订单增加状态和各状态的时间，新增订单状态变更记录表。
+// 已有订单没有支付记录，统一记为待支付
+var orderStatus = Migration{
+	Version: 13,
+	Name:    "order_status",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range orderStatusFields {
+			if err := m.AddColumn(&orderV13{}, field); err != nil {
+				return err
+			}
+		}
+		if err := m.CreateIndex(&orderV13{}, "Status"); err != nil {
+			return err
+		}
+		return m.CreateTable(&orderEventV13{})
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropTable(&orderEventV13{}); err != nil {
+			return err
+		}
+		if err := m.DropIndex(&orderV13{}, "Status"); err != nil {
+			return err
+		}
+		for i := len(orderStatusFields) - 1; i >= 0; i-- {
+			if err := m.DropColumn(&orderV13{}, orderStatusFields[i]); err != nil {
+				return err
+			}
+		}
+		// 补建订单的用户索引
+		return restoreIndexes(tx, &orderV1{})
+	},
+}
@@ -0,0 +1,35 @@
+package migration
+
+import "gorm.io/gorm"
+
+type orderItemV14 struct {
+	Name  string `gorm:"type:varchar(255);not null;default:''"`
+	Cover string `gorm:"type:varchar(255);not null;default:''"`
+}
+
+func (orderItemV14) TableName() string { return "order_items" }
+
+// orderItemSnapshot 给订单项增加商品名称和封面快照，已有订单项按迁移时的商品信息补齐，包括已删除的商品
+var orderItemSnapshot = Migration{
+	Version: 14,
+	Name:    "order_item_snapshot",
+	Up: func(tx *gorm.DB) error {
+		for _, field := range []string{"Name", "Cover"} {
+			if err := tx.Migrator().AddColumn(&orderItemV14{}, field); err != nil {
+				return err
+			}
+		}
+		return tx.Exec(`UPDATE order_items SET
+			name = COALESCE((SELECT name FROM products WHERE products.product_id = order_items.product_id), ''),
+			cover = COALESCE((SELECT cover FROM products WHERE products.product_id = order_items.product_id), '')`).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		for _, field := range []string{"Cover", "Name"} {
+			if err := tx.Migrator().DropColumn(&orderItemV14{}, field); err != nil {
+				return err
+			}
+		}
+		// 补建订单项的订单索引
+		return restoreIndexes(tx, &orderItemV1{})
+	},
+}
@@ -0,0 +1,31 @@
+package migration
+
+import "gorm.io/gorm"
+
+type productV15 struct {
+	CartAdds int64 `gorm:"not null;default:0"`
+}
+
+func (productV15) TableName() string { return "products" }
+
+// productCartAdds 给商品增加累计加购次数。
+// 此前没有记录加购次数，已有商品按用户购物车（含已移除的）和游客购物车中的记录数估算
+var productCartAdds = Migration{
+	Version: 15,
+	Name:    "product_cart_adds",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&productV15{}, "CartAdds"); err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE products SET cart_adds =
+			(SELECT COUNT(*) FROM carts WHERE carts.product_id = products.product_id) +
+			(SELECT COUNT(*) FROM guest_cart_items WHERE guest_cart_items.product_id = products.product_id)`).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropColumn(&productV15{}, "CartAdds"); err != nil {
+			return err
+		}
+		// 补建商品类型、软删除和更新时间索引
+		return restoreIndexes(tx, &productV1{}, &productV5{}, &productV6{})
+	},
+}
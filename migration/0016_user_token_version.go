@@ -0,0 +1,48 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userV16 struct {
+	TokenVersion    uint `gorm:"not null;default:0"`
+	TokensRevokedAt *time.Time
+}
+
+func (userV16) TableName() string { return "users" }
+
+// userTokenVersion 把批量吊销的时间点换成 token 版本。
+// iat 只精确到秒，按时间点比较时，退出所有设备后同一秒内重新登录签发的 token 也会被拒绝。
+// 已经批量吊销过的用户版本记为 1，迁移前签发的 token 不带版本，这些用户需要重新登录
+var userTokenVersion = Migration{
+	Version: 16,
+	Name:    "user_token_version",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&userV16{}, "TokenVersion"); err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE users SET token_version = 1 WHERE tokens_revoked_at IS NOT NULL").Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropColumn(&userV16{}, "TokensRevokedAt"); err != nil {
+			return err
+		}
+		// 补建用户名唯一索引
+		return restoreIndexes(tx, &userV1{})
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&userV16{}, "TokensRevokedAt"); err != nil {
+			return err
+		}
+		// 版本不为 0 的用户按回滚时间吊销，已经签发的 token 全部失效
+		if err := tx.Exec("UPDATE users SET tokens_revoked_at = ? WHERE token_version > 0", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropColumn(&userV16{}, "TokenVersion"); err != nil {
+			return err
+		}
+		return restoreIndexes(tx, &userV1{})
+	},
+}
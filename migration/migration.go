@@ -0,0 +1,136 @@
+package migration
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 定义一次版本化的表结构变更。
+// Up 和 Down 中只能使用迁移文件内定义的结构体快照，不要引用 models 包，
+// 否则模型后续的修改会悄悄改变已经执行过的迁移。
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration 记录已经执行的迁移版本
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定迁移记录表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Up 按版本顺序执行所有尚未执行的迁移
+func Up(db *gorm.DB) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range sorted() {
+		if applied[m.Version] {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("applied migration %d_%s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移
+func Down(db *gorm.DB, steps int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	all := sorted()
+	for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+		m := all[i]
+		if !applied[m.Version] {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("rolled back migration %d_%s", m.Version, m.Name)
+		steps--
+	}
+	return nil
+}
+
+// appliedVersions 读取已经执行的迁移版本，必要时创建迁移记录表
+func appliedVersions(db *gorm.DB) (map[int]bool, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	applied := make(map[int]bool, len(records))
+	for _, r := range records {
+		applied[r.Version] = true
+	}
+	return applied, nil
+}
+
+// sorted 返回按版本升序排列的迁移列表
+func sorted() []Migration {
+	all := make([]Migration, len(migrations))
+	copy(all, migrations)
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all
+}
+
+// restoreIndexes 创建 models 上声明但表中还不存在的索引。
+// SQLite 删除列、增删约束时会重建表，旧表上的索引随旧表一起删除，这类操作之后需要调用；
+// 其他数据库上索引都还在，不做任何修改
+func restoreIndexes(tx *gorm.DB, models ...interface{}) error {
+	m := tx.Migrator()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		var names []string
+		for name := range stmt.Schema.ParseIndexes() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if m.HasIndex(model, name) {
+				continue
+			}
+			if err := m.CreateIndex(model, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
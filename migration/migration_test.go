@@ -0,0 +1,141 @@
+package migration
+
+import (
+	"awesomeProject/storage"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// schemaOf 返回数据库中全部表的列和索引，用于比较迁移前后的表结构
+func schemaOf(t *testing.T, db *gorm.DB) map[string][]string {
+	t.Helper()
+	var tables []string
+	if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+	schema := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		var entries []string
+		var columns []struct {
+			Name    string
+			Type    string
+			NotNull bool
+		}
+		if err := db.Raw(fmt.Sprintf("SELECT name, type, \"notnull\" AS not_null FROM pragma_table_info('%s')", table)).Scan(&columns).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range columns {
+			entries = append(entries, fmt.Sprintf("column %s %s notnull=%v", c.Name, c.Type, c.NotNull))
+		}
+		var indexes []string
+		if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ?", table).Scan(&indexes).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range indexes {
+			entries = append(entries, "index "+index)
+		}
+		sort.Strings(entries)
+		schema[table] = entries
+	}
+	return schema
+}
+
+func openMigrated(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := storage.OpenInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUpCreatesIndexes(t *testing.T) {
+	db := openMigrated(t)
+	// SQLite 删除列会重建表，这些索引曾经因此丢失
+	for _, index := range []string{
+		"idx_users_username",
+		"idx_products_type",
+		"idx_products_deleted_at",
+		"idx_products_updated_at",
+		"idx_carts_user_product",
+		"idx_orders_user_id",
+		"idx_orders_status",
+		"idx_order_items_order_id",
+	} {
+		var count int64
+		if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?", index).Scan(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("index %s is missing after Up", index)
+		}
+	}
+}
+
+func TestDownAndUpAgain(t *testing.T) {
+	db := openMigrated(t)
+	want := schemaOf(t, db)
+
+	// 回滚最近 n 个迁移后重新执行，表结构应与一次执行全部迁移时完全一致
+	for n := 1; n <= len(migrations); n++ {
+		if err := Down(db, n); err != nil {
+			t.Fatalf("Down(%d): %v", n, err)
+		}
+		if err := Up(db); err != nil {
+			t.Fatalf("Up after Down(%d): %v", n, err)
+		}
+		if got := schemaOf(t, db); !reflect.DeepEqual(got, want) {
+			for table := range want {
+				if !reflect.DeepEqual(got[table], want[table]) {
+					t.Errorf("after Down(%d) and Up, table %s = %v, want %v", n, table, got[table], want[table])
+				}
+			}
+		}
+	}
+}
+
+func TestDownAll(t *testing.T) {
+	db := openMigrated(t)
+	if err := Down(db, len(migrations)); err != nil {
+		t.Fatal(err)
+	}
+	schema := schemaOf(t, db)
+	delete(schema, "schema_migrations")
+	if len(schema) != 0 {
+		t.Errorf("tables left after rolling back every migration: %v", schema)
+	}
+}
+
+func TestMoneyCentsConvertsExistingRows(t *testing.T) {
+	db := openMigrated(t)
+	var steps int
+	for _, m := range migrations {
+		if m.Version >= moneyCents.Version {
+			steps++
+		}
+	}
+	if err := Down(db, steps); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO products (product_id, name, price) VALUES ('p1', 'book', 19.99), ('p2', 'pen', 0.1)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var prices []int64
+	if err := db.Raw("SELECT price FROM products ORDER BY product_id").Scan(&prices).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(prices, []int64{1999, 10}) {
+		t.Errorf("prices after money_cents = %v, want [1999 10]", prices)
+	}
+}
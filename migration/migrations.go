@@ -0,0 +1,21 @@
+package migration
+
+// migrations 全部迁移，新增迁移时追加到末尾并使用递增的版本号
+var migrations = []Migration{
+	initialSchema,
+	refreshTokens,
+	tokenRevocation,
+	roles,
+	productSoftDelete,
+	productTimestamps,
+	dropIsAddedCart,
+	cartQuantity,
+	moneyCents,
+	cartUserForeignKey,
+	guestCarts,
+	orderPricing,
+	orderStatus,
+	orderItemSnapshot,
+	productCartAdds,
+	userTokenVersion,
+}
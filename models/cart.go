@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// Cart 定义购物车表，每行表示用户购物车中的一种商品及其数量。
+// 同一用户的同一商品只有一行（包括已软删除的行），再次加入时更新原有的行。
+// UserID 是 users 的外键，删除用户时一并删除其购物车。
+type Cart struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index;uniqueIndex:idx_carts_user_product"`
+	User      User   `gorm:"constraint:OnDelete:CASCADE"`
+	ProductID string `gorm:"type:varchar(64);not null;index;uniqueIndex:idx_carts_user_product"`
+	Quantity  uint   `gorm:"not null;default:1"`
+}
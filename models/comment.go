@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Comment 定义商品评论表，PostID 即评论 ID
+type Comment struct {
+	PostID      uint      `gorm:"primaryKey" json:"post_id"`
+	ProductID   string    `gorm:"type:varchar(64);not null;index" json:"product_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	Content     string    `gorm:"type:text;not null" json:"content"`
+	PraiseCount int       `gorm:"not null;default:0" json:"praise_count"`
+	PublishTime time.Time `gorm:"autoCreateTime" json:"publish_time"`
+	UpdatedAt   time.Time `json:"-"`
+}
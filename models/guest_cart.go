@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// GuestCartItem 定义游客购物车表，游客由签名 cookie 中的 GuestID 标识，登录后合并到用户购物车。
+// UpdatedAt 用于定期清理 cookie 已经过期的游客，见 cart.StartGuestCartGC
+type GuestCartItem struct {
+	ID        uint   `gorm:"primaryKey"`
+	GuestID   string `gorm:"type:varchar(64);not null;uniqueIndex:idx_guest_cart_items_guest_product"`
+	ProductID string `gorm:"type:varchar(64);not null;uniqueIndex:idx_guest_cart_items_guest_product"`
+	Quantity  uint   `gorm:"not null;default:1"`
+	CreatedAt time.Time
+	UpdatedAt time.Time `gorm:"index"`
+}
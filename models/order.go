@@ -0,0 +1,61 @@
+package models
+
+import (
+	"awesomeProject/money"
+	"time"
+)
+
+// 订单状态
+const (
+	OrderStatusPendingPayment = "pending_payment"
+	OrderStatusPaid           = "paid"
+	OrderStatusShipped        = "shipped"
+	OrderStatusDelivered      = "delivered"
+	OrderStatusCompleted      = "completed"
+	OrderStatusCancelled      = "cancelled"
+	OrderStatusRefunded       = "refunded"
+)
+
+// Order 定义订单表，Total 为商品小计之和加运费，均由服务端计算。
+// Status 只能通过 operate/order 中的状态机修改，每个状态第一次进入的时间记录在对应的 *At 字段
+type Order struct {
+	OrderID     uint         `gorm:"primaryKey" json:"order_id"`
+	UserID      uint         `gorm:"not null;index" json:"user_id"`
+	Address     string       `gorm:"type:varchar(255);not null" json:"address"`
+	ShippingFee money.Money  `gorm:"not null;default:0" json:"shipping_fee"`
+	Total       money.Money  `gorm:"not null" json:"total"`
+	Status      string       `gorm:"type:varchar(32);not null;default:pending_payment;index" json:"status"`
+	CreatedAt   time.Time    `json:"created_at"`
+	PaidAt      *time.Time   `json:"paid_at"`
+	ShippedAt   *time.Time   `json:"shipped_at"`
+	DeliveredAt *time.Time   `json:"delivered_at"`
+	CompletedAt *time.Time   `json:"completed_at"`
+	CancelledAt *time.Time   `json:"cancelled_at"`
+	RefundedAt  *time.Time   `json:"refunded_at"`
+	OrderItems  []OrderItem  `gorm:"foreignKey:OrderID" json:"orders"`
+	Events      []OrderEvent `gorm:"foreignKey:OrderID" json:"events,omitempty"`
+}
+
+// OrderItem 定义订单内容中的单个商品项，Name、Cover、UnitPrice 和 Subtotal 是下单时的商品快照，
+// 商品之后被修改或删除也不影响历史订单的展示
+type OrderItem struct {
+	ID        uint        `gorm:"primaryKey" json:"-"`
+	OrderID   uint        `gorm:"not null;index" json:"-"`
+	ProductID string      `gorm:"type:varchar(64);not null" json:"product_id"`
+	Name      string      `gorm:"type:varchar(255);not null;default:''" json:"name"`
+	Cover     string      `gorm:"type:varchar(255);not null;default:''" json:"cover"`
+	Quantity  uint        `gorm:"not null" json:"quantity"`
+	UnitPrice money.Money `gorm:"not null;default:0" json:"unit_price"`
+	Subtotal  money.Money `gorm:"not null;default:0" json:"subtotal"`
+}
+
+// OrderEvent 定义订单状态变更记录，下单时 FromStatus 为空
+type OrderEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	OrderID    uint      `gorm:"not null;index" json:"-"`
+	FromStatus string    `gorm:"type:varchar(32);not null;default:''" json:"from_status"`
+	ToStatus   string    `gorm:"type:varchar(32);not null" json:"to_status"`
+	ActorID    uint      `gorm:"not null" json:"actor_id"`
+	Note       string    `gorm:"type:varchar(255);not null;default:''" json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
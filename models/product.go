@@ -0,0 +1,31 @@
+package models
+
+import (
+	"awesomeProject/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Product 定义商品表，Num 为库存数量。
+// 商品只做软删除，DeletedAt 不为空的商品不会出现在普通查询中；
+// 搜索索引根据 UpdatedAt 和 DeletedAt 增量同步。
+// IsAddedCart 不存储，由接口按调用方的购物车计算，见 cart.MarkAdded。
+// CartAdds 为累计加购次数，只在 cart.AddToCart 中累加，用于搜索建议的热度排序。
+type Product struct {
+	ProductID   string         `gorm:"primaryKey;type:varchar(64)" json:"product_id"`
+	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	Type        string         `gorm:"type:varchar(64);index" json:"type"`
+	CommentNum  int            `gorm:"not null;default:0" json:"comment_num"`
+	Price       money.Money    `gorm:"not null;default:0" json:"price"`
+	IsAddedCart bool           `gorm:"-" json:"is_addedCart"`
+	Cover       string         `gorm:"type:varchar(255)" json:"cover"`
+	PublishTime string         `gorm:"type:varchar(32)" json:"publish_time"`
+	Link        string         `gorm:"type:varchar(255)" json:"link"`
+	Num         int            `gorm:"not null;default:0" json:"num"`
+	CartAdds    int64          `gorm:"not null;default:0" json:"-"`
+	CreatedAt   time.Time      `json:"-"`
+	UpdatedAt   time.Time      `gorm:"index" json:"-"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
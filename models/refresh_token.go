@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RefreshToken 定义服务端保存的刷新 token。
+// 同一次登录轮换出来的刷新 token 共享 FamilyID，任一已使用的 token 被重放时整个 family 一起吊销。
+// 过期的记录由 auth.StartRevocationGC 定期删除。
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	JTI       string    `gorm:"column:jti;type:varchar(64);uniqueIndex;not null"`
+	FamilyID  string    `gorm:"type:varchar(64);index;not null"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
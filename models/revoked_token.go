@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken 定义提前吊销的访问 token。
+// 记录只需要保留到 token 自身过期，之后由后台任务清理。
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
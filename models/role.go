@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// 内置角色
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Role 定义角色表，用户与角色通过 user_roles 多对多关联
+type Role struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	Name      string    `gorm:"type:varchar(32);uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"-"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// User 定义用户表。
+// Roles 会写入访问 token，角色变更在重新签发 token 后生效。
+// TokenVersion 写入访问 token，退出所有设备时加一，版本更小的访问 token 全部失效。
+type User struct {
+	ID           uint      `gorm:"primaryKey" json:"user_id"`
+	Username     string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"username"`
+	Password     string    `gorm:"type:varchar(255);not null" json:"-"`
+	Email        string    `gorm:"type:varchar(255)" json:"email"`
+	TokenVersion uint      `gorm:"not null;default:0" json:"-"`
+	Roles        []Role    `gorm:"many2many:user_roles;" json:"-"`
+	CreatedAt    time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+}
@@ -0,0 +1,113 @@
+package money
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money 定义金额，以分为单位的整数存储，避免浮点数累加产生误差。
+// 数据库中是 BIGINT，JSON 中是保留两位小数的数字，例如 9.80。
+type Money int64
+
+// ErrInvalidAmount 金额格式不正确
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// FromCents 用分数创建金额
+func FromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// Parse 解析十进制金额，例如 "9.8"、"9.80"、"10"，最多两位小数
+func Parse(s string) (Money, error) {
+	input := strings.TrimSpace(s)
+	s = input
+	negative := strings.HasPrefix(s, "-")
+	if negative || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" || hasPoint && frac == "" || len(frac) > 2 {
+		return 0, fmt.Errorf("%w: %q must be a decimal with at most two decimal places", ErrInvalidAmount, input)
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, fmt.Errorf("%w: %q must be a decimal with at most two decimal places", ErrInvalidAmount, input)
+			}
+		}
+	}
+
+	var units, cents int64
+	if whole != "" {
+		n, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil || n > math.MaxInt64/100-1 {
+			return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, input)
+		}
+		units = n
+	}
+	for i := 0; i < 2; i++ {
+		cents *= 10
+		if i < len(frac) {
+			cents += int64(frac[i] - '0')
+		}
+	}
+	m := Money(units*100 + cents)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// Cents 返回以分为单位的金额
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Mul 返回单价乘以数量的金额
+func (m Money) Mul(quantity uint) Money {
+	return m * Money(quantity)
+}
+
+// String 返回保留两位小数的十进制表示
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON 把金额编码为保留两位小数的 JSON 数字
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON 接受 JSON 数字或字符串形式的十进制金额
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if bytes.ContainsAny(data, "eE") {
+		return fmt.Errorf("%w: %s must not use exponent notation", ErrInvalidAmount, data)
+	}
+	parsed, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalText 解析十进制金额，用于从 YAML 等文本格式读取
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
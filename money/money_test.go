@@ -0,0 +1,77 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"0", 0},
+		{"10", 1000},
+		{"9.8", 980},
+		{"9.80", 980},
+		{"0.01", 1},
+		{".5", 50},
+		{" 12.34 ", 1234},
+		{"+1.5", 150},
+		{"-1.05", -105},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{"", "-", ".", "1.", "1.234", "1,00", "abc", "1e2", "1.-5", "99999999999999999999"} {
+		if got, err := Parse(in); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Parse(%q) = %d, %v; want ErrInvalidAmount", in, got, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := map[Money]string{0: "0.00", 5: "0.05", 980: "9.80", 123456: "1234.56", -105: "-1.05"}
+	for m, want := range tests {
+		if got := m.String(); got != want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(m), got, want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var v struct {
+		Price Money `json:"price"`
+	}
+	for _, in := range []string{`{"price":9.8}`, `{"price":"9.80"}`, `{"price":9.80}`} {
+		if err := json.Unmarshal([]byte(in), &v); err != nil || v.Price != 980 {
+			t.Errorf("Unmarshal(%s) = %d, %v; want 980", in, v.Price, err)
+		}
+	}
+	out, err := json.Marshal(v)
+	if err != nil || string(out) != `{"price":9.80}` {
+		t.Errorf("Marshal = %s, %v; want {\"price\":9.80}", out, err)
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	for _, in := range []string{`1e2`, `"1E2"`, `9.999`, `"abc"`, `true`} {
+		var m Money
+		if err := m.UnmarshalJSON([]byte(in)); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("UnmarshalJSON(%s) = %v; want ErrInvalidAmount", in, err)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	if got := Money(980).Mul(3); got != 2940 {
+		t.Errorf("Mul = %d, want 2940", got)
+	}
+}
@@ -0,0 +1,106 @@
+package order
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"awesomeProject/product/cart"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// CheckoutRequest 定义购物车结算请求体，商品取自购物车，Total 必须与服务端报价一致
+type CheckoutRequest struct {
+	Address string      `json:"address"`
+	Total   money.Money `json:"total"`
+}
+
+// errEmptyCart 购物车为空，没有可结算的商品
+var errEmptyCart = errors.New("cart is empty")
+
+// checkout 在一个事务中把用户购物车下单并从购物车删除已下单的商品，失败时购物车保持不变
+func checkout(db *gorm.DB, userID uint, req CheckoutRequest) (*models.Order, *Quote, error) {
+	var newOrder *models.Order
+	var quote *Quote
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 已下架的商品在购物车中不显示也不计价，结算时跳过并从购物车删除
+		lines, unavailable, err := cart.UserLines(tx, userID)
+		if err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return errEmptyCart
+		}
+		orderReq := OrderRequest{Address: req.Address, Total: req.Total}
+		productIDs := make([]string, 0, len(lines)+len(unavailable))
+		for _, line := range lines {
+			orderReq.Orders = append(orderReq.Orders, OrderItemRequest{ProductID: line.ProductID, Quantity: line.Quantity})
+			productIDs = append(productIDs, line.ProductID)
+		}
+		productIDs = append(productIDs, unavailable...)
+
+		newOrder, quote, err = createOrder(tx, userID, orderReq)
+		if err != nil {
+			return err
+		}
+		// 只删除读到的商品，结算期间新加入购物车的商品保留
+		return cart.RemoveUserItems(tx, userID, productIDs)
+	})
+	if err != nil {
+		return nil, quote, err
+	}
+	return newOrder, quote, nil
+}
+
+// CheckoutHandler 结算当前用户的购物车，成功后清空购物车
+func CheckoutHandler(ctx context.Context, c *app.RequestContext) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 401,
+		})
+		return
+	}
+
+	var req CheckoutRequest
+	if err := c.Bind(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   fmt.Sprintf("failed to bind request: %v", err),
+			"status": 400,
+		})
+		return
+	}
+	if req.Address == "" {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "address is required",
+			"status": 400,
+		})
+		return
+	}
+
+	newOrder, quote, err := checkout(DB, principal.UserID, req)
+	if err != nil {
+		if errors.Is(err, errEmptyCart) {
+			c.JSON(consts.StatusBadRequest, utils.H{
+				"info":   err.Error(),
+				"status": 400,
+			})
+			return
+		}
+		respondPlaceOrderError(c, err, req.Total, quote)
+		return
+	}
+
+	c.JSON(consts.StatusOK, utils.H{
+		"info":     "success",
+		"status":   10000,
+		"order_id": strconv.Itoa(int(newOrder.OrderID)),
+	})
+}
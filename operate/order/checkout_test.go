@@ -0,0 +1,91 @@
+package order
+
+import (
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fillCart 创建测试用户，并把 quantities 中的商品写入其购物车
+func fillCart(t *testing.T, db *gorm.DB, quantities map[string]uint) {
+	t.Helper()
+	if err := db.Create(&models.User{ID: testUserID, Username: "bob", Password: "secret123"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for productID, quantity := range quantities {
+		if err := db.Create(&models.Cart{UserID: testUserID, ProductID: productID, Quantity: quantity}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func cartSize(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.Cart{}).Where("user_id = ?", testUserID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestCheckoutSkipsDelistedProducts(t *testing.T) {
+	db := openTestDB(t)
+	delisted := models.Product{ProductID: "p2", Name: "pen", Price: money.FromCents(200), Num: 3}
+	if err := db.Create(&delisted).Error; err != nil {
+		t.Fatal(err)
+	}
+	fillCart(t, db, map[string]uint{"p1": 2, "p2": 1})
+	if err := db.Delete(&delisted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 总额只包含在售的 p1
+	order, quote, err := checkout(db, testUserID, CheckoutRequest{Address: "somewhere", Total: money.FromCents(2000)})
+	if err != nil {
+		t.Fatalf("checkout() error = %v, quote %+v", err, quote)
+	}
+	var items []models.OrderItem
+	if err := db.Where("order_id = ?", order.OrderID).Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ProductID != "p1" || items[0].Quantity != 2 {
+		t.Errorf("order items = %+v, want only p1 x2", items)
+	}
+	if got := stockOf(t, db); got != 3 {
+		t.Errorf("stock after checkout = %d, want 3", got)
+	}
+	// 已下单和已下架的商品都从购物车删除
+	if got := cartSize(t, db); got != 0 {
+		t.Errorf("cart size after checkout = %d, want 0", got)
+	}
+}
+
+func TestCheckoutEmptyCart(t *testing.T) {
+	db := openTestDB(t)
+	fillCart(t, db, nil)
+	if _, _, err := checkout(db, testUserID, CheckoutRequest{Address: "somewhere"}); !errors.Is(err, errEmptyCart) {
+		t.Errorf("checkout() error = %v, want errEmptyCart", err)
+	}
+}
+
+func TestCheckoutKeepsCartOnTotalMismatch(t *testing.T) {
+	db := openTestDB(t)
+	fillCart(t, db, map[string]uint{"p1": 2})
+
+	_, quote, err := checkout(db, testUserID, CheckoutRequest{Address: "somewhere", Total: money.FromCents(1000)})
+	if !errors.Is(err, errTotalMismatch) {
+		t.Fatalf("checkout() error = %v, want errTotalMismatch", err)
+	}
+	if quote == nil || quote.Total != money.FromCents(2000) {
+		t.Errorf("quote = %+v, want total 20.00", quote)
+	}
+	if got := cartSize(t, db); got != 1 {
+		t.Errorf("cart size after failed checkout = %d, want 1", got)
+	}
+	if got := stockOf(t, db); got != 5 {
+		t.Errorf("stock after failed checkout = %d, want 5", got)
+	}
+}
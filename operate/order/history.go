@@ -0,0 +1,196 @@
+package order
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/models"
+	"awesomeProject/pagination"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"log"
+	"strconv"
+	"time"
+)
+
+// dateLayout 日期范围参数只写日期时使用的格式
+const dateLayout = "2006-01-02"
+
+// OrderListData 定义订单列表数据，分页信息与订单列表平铺在同一层
+type OrderListData struct {
+	Orders []models.Order `json:"orders"`
+	pagination.Meta
+}
+
+// parseTimeParam 解析日期范围参数，支持 YYYY-MM-DD 和 RFC 3339。
+// 只写日期时 from 取当天零点，to 取次日零点，使 to 当天的订单也包含在内
+func parseTimeParam(c *app.RequestContext, name string, endOfDay bool) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be formatted as YYYY-MM-DD or RFC 3339", name)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// orderFilters 按查询参数 status、from、to 给订单查询加上过滤条件
+func orderFilters(c *app.RequestContext, query *gorm.DB) (*gorm.DB, error) {
+	if status := c.Query("status"); status != "" {
+		if !validStatus(status) {
+			return nil, errors.New("status must be one of pending_payment, paid, shipped, delivered, completed, cancelled, refunded")
+		}
+		query = query.Where("status = ?", status)
+	}
+	from, err := parseTimeParam(c, "from", false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeParam(c, "to", true)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, errors.New("from must be before to")
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	return query, nil
+}
+
+// loadOrder 读取 query 匹配的订单及其商品项和状态变更记录
+func loadOrder(query *gorm.DB) (*models.Order, error) {
+	var order models.Order
+	err := query.Preload("OrderItems", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("id")
+	}).Preload("Events", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("id")
+	}).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrdersHandler 分页获取当前用户的订单，按下单时间从新到旧排列。
+// 支持 page/page_size 偏移分页和 cursor 游标分页，status 按状态过滤，from、to 按下单时间过滤
+func ListOrdersHandler(ctx context.Context, c *app.RequestContext) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 401,
+		})
+		return
+	}
+	params, err := pagination.FromRequest(c)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 400,
+		})
+		return
+	}
+	query, err := orderFilters(c, DB.Model(&models.Order{}).Where("user_id = ?", principal.UserID))
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 400,
+		})
+		return
+	}
+
+	orders := []models.Order{}
+	// order_id 随下单时间递增，按 order_id 倒序即按下单时间从新到旧
+	total, err := params.Fetch(query, "order_id", true, "order_id", "order_id", func(page *gorm.DB) error {
+		return page.Preload("OrderItems", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("id")
+		}).Find(&orders).Error
+	})
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 400,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to list orders of user %d: %v", principal.UserID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Database query error",
+			"status": 500,
+		})
+		return
+	}
+
+	fetched := len(orders)
+	if fetched > params.PageSize {
+		orders = orders[:params.PageSize]
+	}
+	meta := params.Meta(total, fetched, "order_id", true, func() (interface{}, interface{}) {
+		last := orders[len(orders)-1]
+		return last.OrderID, last.OrderID
+	})
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   OrderListData{Orders: orders, Meta: meta},
+	})
+}
+
+// GetOrderHandler 获取当前用户的一个订单，包括商品快照和状态变更记录；订单不属于调用方时同样返回 404
+func GetOrderHandler(ctx context.Context, c *app.RequestContext) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 401,
+		})
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "invalid order_id",
+			"status": 400,
+		})
+		return
+	}
+
+	order, err := loadOrder(DB.Where("order_id = ? AND user_id = ?", orderID, principal.UserID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{
+				"info":   errOrderNotFound.Error(),
+				"status": 404,
+			})
+			return
+		}
+		log.Printf("Failed to load order %d: %v", orderID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Database query error",
+			"status": 500,
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   order,
+	})
+}
@@ -0,0 +1,156 @@
+package order
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+)
+
+// newTestServer 返回注册了订单路由的服务，数据库中有用户 bob（testUserID）和 eve
+func newTestServer(t *testing.T) (*server.Hertz, *gorm.DB) {
+	t.Helper()
+	db := openTestDB(t)
+	cfg := config.Default()
+	// 不收运费，与 newTestOrder 的总额一致
+	cfg.Order = config.OrderConfig{}
+	if err := auth.InitDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range []models.User{
+		{ID: testUserID, Username: "bob", Password: "secret123"},
+		{ID: testUserID + 1, Username: "eve", Password: "secret123"},
+	} {
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := server.New()
+	RegisterRoutes(h)
+	return h, db
+}
+
+func accessToken(t *testing.T, userID uint, username string) string {
+	t.Helper()
+	claims, err := auth.NewClaims(auth.TokenTypeAccess, auth.Principal{UserID: userID, Username: username}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := auth.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func getWithToken(h *server.Hertz, url, token string) *protocol.Response {
+	return ut.PerformRequest(h.Engine, consts.MethodGet, url, nil,
+		ut.Header{Key: "Authorization", Value: "Bearer " + token},
+	).Result()
+}
+
+// listOrders 请求订单列表并返回订单 ID 和分页信息
+func listOrders(t *testing.T, h *server.Hertz, url, token string) ([]uint, OrderListData) {
+	t.Helper()
+	resp := getWithToken(h, url, token)
+	if resp.StatusCode() != consts.StatusOK {
+		t.Fatalf("GET %s: status %d, body %s", url, resp.StatusCode(), resp.Body())
+	}
+	var out struct {
+		Data OrderListData `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body(), &out); err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint, 0, len(out.Data.Orders))
+	for _, order := range out.Data.Orders {
+		ids = append(ids, order.OrderID)
+	}
+	return ids, out.Data
+}
+
+func TestListOrdersPaginates(t *testing.T) {
+	h, db := newTestServer(t)
+	var bobOrders []uint
+	for i := 0; i < 3; i++ {
+		bobOrders = append(bobOrders, newTestOrder(t, db, 1).OrderID)
+		if i == 2 {
+			break
+		}
+		// 其他用户的订单穿插其中，不出现在列表中
+		if _, _, err := placeOrder(db, testUserID+1, OrderRequest{
+			Orders:  []OrderItemRequest{{ProductID: "p1", Quantity: 1}},
+			Address: "elsewhere",
+			Total:   money.FromCents(1000),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := accessToken(t, testUserID, "bob")
+
+	first, meta := listOrders(t, h, "/operate/order?page_size=2", token)
+	if want := []uint{bobOrders[2], bobOrders[1]}; fmt.Sprint(first) != fmt.Sprint(want) {
+		t.Errorf("first page = %v, want %v", first, want)
+	}
+	if meta.Total != 3 || meta.NextCursor == "" {
+		t.Fatalf("first page meta = %+v, want total 3 and a next cursor", meta.Meta)
+	}
+
+	second, meta := listOrders(t, h, "/operate/order?page_size=2&cursor="+url.QueryEscape(meta.NextCursor), token)
+	if want := []uint{bobOrders[0]}; fmt.Sprint(second) != fmt.Sprint(want) {
+		t.Errorf("second page = %v, want %v", second, want)
+	}
+	if meta.NextCursor != "" {
+		t.Errorf("second page next cursor = %q, want none", meta.NextCursor)
+	}
+
+	if resp := getWithToken(h, "/operate/order?cursor=garbage", token); resp.StatusCode() != consts.StatusBadRequest {
+		t.Errorf("invalid cursor: status %d, want 400", resp.StatusCode())
+	}
+}
+
+func TestGetOrderOwnerOnly(t *testing.T) {
+	h, db := newTestServer(t)
+	order := newTestOrder(t, db, 2)
+	path := fmt.Sprintf("/operate/order/%d", order.OrderID)
+
+	resp := getWithToken(h, path, accessToken(t, testUserID, "bob"))
+	if resp.StatusCode() != consts.StatusOK {
+		t.Fatalf("owner: status %d, body %s", resp.StatusCode(), resp.Body())
+	}
+	var out struct {
+		Data models.Order `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Data.OrderID != order.OrderID || len(out.Data.OrderItems) != 1 || len(out.Data.Events) != 1 {
+		t.Errorf("order = %+v, want its item and initial event", out.Data)
+	}
+
+	// 订单不属于调用方时与订单不存在一样返回 404
+	eve := accessToken(t, testUserID+1, "eve")
+	if resp := getWithToken(h, path, eve); resp.StatusCode() != consts.StatusNotFound {
+		t.Errorf("other user: status %d, want 404", resp.StatusCode())
+	}
+	if resp := getWithToken(h, "/operate/order/9999", eve); resp.StatusCode() != consts.StatusNotFound {
+		t.Errorf("missing order: status %d, want 404", resp.StatusCode())
+	}
+	if resp := getWithToken(h, "/operate/order/abc", eve); resp.StatusCode() != consts.StatusBadRequest {
+		t.Errorf("invalid order id: status %d, want 400", resp.StatusCode())
+	}
+}
@@ -1,68 +1,133 @@
-package main
+package order
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/money"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"strconv"
 	"time"
 )
 
-// OrderItem 定义订单内容中的单个商品项结构体
-type OrderItem struct {
-	ID        uint `gorm:"primaryKey"`
-	OrderID   uint
-	ProductID uint
-	Quantity  uint
+// OrderItemRequest 定义下单请求中的单个商品项
+type OrderItemRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  uint   `json:"quantity"`
 }
 
-// Order 定义订单结构体
-type Order struct {
-	OrderID    uint `gorm:"primaryKey"`
-	UserID     uint
-	Address    string
-	Total      float64
-	CreatedAt  time.Time
-	OrderItems []OrderItem `gorm:"foreignKey:OrderID"`
-}
-
-// OrderRequest 定义下单请求结构体
+// OrderRequest 定义下单请求结构体，Total 必须与服务端报价一致
 type OrderRequest struct {
-	UserID  uint        `json:"user_id"`
-	Orders  []OrderItem `json:"orders"`
-	Address string      `json:"address"`
-	Total   float64     `json:"total"`
+	Orders  []OrderItemRequest `json:"orders"`
+	Address string             `json:"address"`
+	Total   money.Money        `json:"total"`
 }
 
 var DB *gorm.DB
 
-// 初始化数据库连接
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// pricing 订单运费配置
+var pricing config.OrderConfig
+
+// InitDB 注入共享的数据库连接和订单计价配置
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	pricing = cfg.Order
+	return nil
+}
+
+// errTotalMismatch 客户端提交的总额与服务端报价不一致
+var errTotalMismatch = errors.New("total does not match the quote")
+
+// errInsufficientStock 商品库存不足
+var errInsufficientStock = errors.New("insufficient stock")
+
+// placeOrder 在一个事务中按服务端报价创建订单和订单项并扣减库存，任何一步失败都整体回滚。
+// 总额不一致时返回 errTotalMismatch 和最新报价，库存不足时返回 errInsufficientStock。
+func placeOrder(db *gorm.DB, userID uint, req OrderRequest) (*models.Order, *Quote, error) {
+	var newOrder *models.Order
+	var quote *Quote
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		newOrder, quote, err = createOrder(tx, userID, req)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
+		return nil, quote, err
 	}
-	// 自动迁移表结构
-	err = DB.AutoMigrate(&Order{}, &OrderItem{})
+	return newOrder, quote, nil
+}
+
+// createOrder 在事务 tx 中创建订单并扣减库存，见 placeOrder。
+// 库存用条件更新 num >= 数量 扣减，并发下单抢最后一件时只有一个事务能更新成功。
+func createOrder(tx *gorm.DB, userID uint, req OrderRequest) (*models.Order, *Quote, error) {
+	quote, err := buildQuote(tx, req.Orders)
 	if err != nil {
-		return fmt.Errorf("failed to auto - migrate database: %w", err)
+		return nil, nil, err
 	}
-	return nil
+	if req.Total != quote.Total {
+		return nil, quote, errTotalMismatch
+	}
+
+	newOrder := models.Order{
+		UserID:      userID,
+		Address:     req.Address,
+		ShippingFee: quote.ShippingFee,
+		Total:       quote.Total,
+		Status:      models.OrderStatusPendingPayment,
+		CreatedAt:   time.Now(),
+	}
+	if err := tx.Create(&newOrder).Error; err != nil {
+		return nil, quote, err
+	}
+	if err := tx.Create(&models.OrderEvent{
+		OrderID:  newOrder.OrderID,
+		ToStatus: models.OrderStatusPendingPayment,
+		ActorID:  userID,
+	}).Error; err != nil {
+		return nil, quote, err
+	}
+	for _, item := range quote.Items {
+		// 只改库存，不更新 updated_at，避免触发搜索索引同步
+		result := tx.Model(&models.Product{}).
+			Where("product_id = ? AND num >= ?", item.ProductID, item.Quantity).
+			UpdateColumn("num", gorm.Expr("num - ?", item.Quantity))
+		if result.Error != nil {
+			return nil, quote, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, quote, fmt.Errorf("%w for product %s", errInsufficientStock, item.ProductID)
+		}
+
+		orderItem := models.OrderItem{
+			OrderID:   newOrder.OrderID,
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Cover:     item.Cover,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Subtotal:  item.Subtotal,
+		}
+		if err := tx.Create(&orderItem).Error; err != nil {
+			return nil, quote, err
+		}
+	}
+	return &newOrder, quote, nil
 }
 
 // PlaceOrderHandler 下单处理函数
 func PlaceOrderHandler(ctx context.Context, c *app.RequestContext) {
-	token := c.Request.Header.Get("Authorization")
-	if token == "" {
+	// 下单用户以 token 为准，不信任请求体中的 user_id
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
 		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Authorization token is required",
+			"info":   "Unauthorized",
 			"status": 401,
 		})
 		return
@@ -78,14 +143,6 @@ func PlaceOrderHandler(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	if req.UserID == 0 {
-		c.JSON(consts.StatusBadRequest, utils.H{
-			"info":   "user_id is required",
-			"status": 400,
-		})
-		return
-	}
-
 	if len(req.Orders) == 0 {
 		c.JSON(consts.StatusBadRequest, utils.H{
 			"info":   "orders is required",
@@ -102,14 +159,6 @@ func PlaceOrderHandler(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	if req.Total <= 0 {
-		c.JSON(consts.StatusBadRequest, utils.H{
-			"info":   "total should be greater than 0",
-			"status": 400,
-		})
-		return
-	}
-
 	if DB == nil {
 		c.JSON(consts.StatusInternalServerError, utils.H{
 			"info":   "Database connection is nil",
@@ -118,38 +167,12 @@ func PlaceOrderHandler(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	newOrder := Order{
-		UserID:    req.UserID,
-		Address:   req.Address,
-		Total:     req.Total,
-		CreatedAt: time.Now(),
-	}
-	result := DB.Create(&newOrder)
-	if result.Error != nil {
-		c.JSON(consts.StatusInternalServerError, utils.H{
-			"info":   fmt.Sprintf("failed to create order: %v", result.Error),
-			"status": 500,
-		})
+	newOrder, quote, err := placeOrder(DB, principal.UserID, req)
+	if err != nil {
+		respondPlaceOrderError(c, err, req.Total, quote)
 		return
 	}
 
-	for _, item := range req.Orders {
-		// 明确传递字段值
-		orderItem := OrderItem{
-			OrderID:   newOrder.OrderID,
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-		}
-		result = DB.Create(&orderItem)
-		if result.Error != nil {
-			c.JSON(consts.StatusInternalServerError, utils.H{
-				"info":   fmt.Sprintf("failed to create order item: %v", result.Error),
-				"status": 500,
-			})
-			return
-		}
-	}
-
 	c.JSON(consts.StatusOK, utils.H{
 		"info":     "success",
 		"status":   10000,
@@ -157,13 +180,33 @@ func PlaceOrderHandler(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
-func main() {
-	err := InitDB()
-	if err != nil {
-		fmt.Printf("Failed to initialize database: %v", err)
-		return
+// respondPlaceOrderError 按 placeOrder 的错误类型返回响应，总额不一致时附上最新报价
+func respondPlaceOrderError(c *app.RequestContext, err error, total money.Money, quote *Quote) {
+	switch {
+	case errors.Is(err, errTotalMismatch):
+		// 价格以服务端报价为准，客户端展示的总额与报价不一致时返回最新报价，由用户确认后重新下单
+		c.JSON(consts.StatusConflict, utils.H{
+			"info":   fmt.Sprintf("total %s does not match the quoted total %s", total, quote.Total),
+			"status": 409,
+			"data":   quote,
+		})
+	case errors.Is(err, errInsufficientStock):
+		c.JSON(consts.StatusConflict, utils.H{
+			"info":   err.Error(),
+			"status": 409,
+		})
+	default:
+		respondQuoteError(c, err)
 	}
-	h := server.New(server.WithHostPorts("127.0.0.1:8017"))
-	h.POST("/operate/order", PlaceOrderHandler)
-	h.Spin()
+}
+
+// RegisterRoutes 注册下单、购物车结算、报价、订单查询和订单状态路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/operate/order", auth.JWTAuthorization(), ListOrdersHandler)
+	r.GET("/operate/order/:order_id", auth.JWTAuthorization(), GetOrderHandler)
+	r.POST("/operate/order", auth.JWTAuthorization(), PlaceOrderHandler)
+	r.POST("/operate/order/checkout", auth.JWTAuthorization(), CheckoutHandler)
+	r.POST("/operate/order/quote", auth.JWTAuthorization(), QuoteHandler)
+	r.POST("/operate/order/:order_id/cancel", auth.JWTAuthorization(), CancelOrderHandler)
+	r.PUT("/admin/order/:order_id/status", auth.JWTAuthorization(), auth.RequireRole(models.RoleAdmin), UpdateStatusHandler)
 }
@@ -0,0 +1,82 @@
+package order
+
+import (
+	"awesomeProject/migration"
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"awesomeProject/storage"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+const testUserID = 1
+
+// openTestDB 返回执行过全部迁移的内存数据库，商品 p1 单价 10.00，库存 5
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := storage.OpenInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Product{ProductID: "p1", Name: "book", Price: money.FromCents(1000), Num: 5}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestOrder 下单购买 quantity 件 p1，运费配置为零值时总额为 10.00 * quantity
+func newTestOrder(t *testing.T, db *gorm.DB, quantity uint) *models.Order {
+	t.Helper()
+	req := OrderRequest{
+		Orders:  []OrderItemRequest{{ProductID: "p1", Quantity: quantity}},
+		Address: "somewhere",
+		Total:   money.FromCents(1000).Mul(quantity),
+	}
+	order, _, err := placeOrder(db, testUserID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func stockOf(t *testing.T, db *gorm.DB) int {
+	t.Helper()
+	var product models.Product
+	if err := db.Unscoped().First(&product, "product_id = ?", "p1").Error; err != nil {
+		t.Fatal(err)
+	}
+	return product.Num
+}
+
+func TestPlaceOrderReservesStock(t *testing.T) {
+	db := openTestDB(t)
+	order := newTestOrder(t, db, 2)
+	if got := stockOf(t, db); got != 3 {
+		t.Errorf("stock after ordering 2 = %d, want 3", got)
+	}
+	var items []models.OrderItem
+	if err := db.Where("order_id = ?", order.OrderID).Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Quantity != 2 || items[0].Subtotal != 2000 {
+		t.Errorf("order items = %+v, want one item of p1 with subtotal 20.00", items)
+	}
+}
+
+func TestPlaceOrderRollsBackWhenStockRunsOut(t *testing.T) {
+	db := openTestDB(t)
+	req := OrderRequest{Orders: []OrderItemRequest{{ProductID: "p1", Quantity: 6}}, Address: "somewhere", Total: 6000}
+	if _, _, err := placeOrder(db, testUserID, req); !errors.Is(err, errInsufficientStock) {
+		t.Fatalf("placeOrder = %v, want errInsufficientStock", err)
+	}
+	var orders int64
+	db.Model(&models.Order{}).Count(&orders)
+	if orders != 0 || stockOf(t, db) != 5 {
+		t.Errorf("after a failed order: %d orders and stock %d, want 0 and 5", orders, stockOf(t, db))
+	}
+}
@@ -0,0 +1,140 @@
+package order
+
+import (
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+)
+
+// QuoteItem 定义报价中的单个商品项
+type QuoteItem struct {
+	ProductID string      `json:"product_id"`
+	Name      string      `json:"name"`
+	Cover     string      `json:"cover"`
+	UnitPrice money.Money `json:"unit_price"`
+	Quantity  uint        `json:"quantity"`
+	Subtotal  money.Money `json:"subtotal"`
+}
+
+// Quote 定义服务端按商品当前价格计算的订单报价
+type Quote struct {
+	Items       []QuoteItem `json:"items"`
+	ItemsTotal  money.Money `json:"items_total"`
+	ShippingFee money.Money `json:"shipping_fee"`
+	Total       money.Money `json:"total"`
+}
+
+// maxItemQuantity 单个商品在一个订单中的最大数量。
+// 数量由客户端提供，不限制时过大的数量会让小计溢出成负数
+const maxItemQuantity = 9999
+
+// errInvalidOrderItem 订单项缺少字段、数量超过上限或商品不存在
+var errInvalidOrderItem = errors.New("invalid order item")
+
+// buildQuote 计算订单报价，同一商品的多个订单项合并为一项
+func buildQuote(db *gorm.DB, items []OrderItemRequest) (*Quote, error) {
+	var productIDs []string
+	quantities := make(map[string]uint, len(items))
+	for _, item := range items {
+		if item.ProductID == "" || item.Quantity == 0 {
+			return nil, fmt.Errorf("%w: product_id and a positive quantity are required", errInvalidOrderItem)
+		}
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		// 先比较再相加，数量本身很大时相加也不会回绕
+		if item.Quantity > maxItemQuantity-quantities[item.ProductID] {
+			return nil, fmt.Errorf("%w: quantity of product %s exceeds %d", errInvalidOrderItem, item.ProductID, maxItemQuantity)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	var products []models.Product
+	if err := db.Where("product_id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ProductID] = product
+	}
+
+	quote := &Quote{Items: make([]QuoteItem, 0, len(productIDs))}
+	for _, id := range productIDs {
+		product, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %s not found", errInvalidOrderItem, id)
+		}
+		subtotal := product.Price.Mul(quantities[id])
+		quote.Items = append(quote.Items, QuoteItem{
+			ProductID: id,
+			Name:      product.Name,
+			Cover:     product.Cover,
+			UnitPrice: product.Price,
+			Quantity:  quantities[id],
+			Subtotal:  subtotal,
+		})
+		quote.ItemsTotal += subtotal
+	}
+	quote.ShippingFee = shippingFee(quote.ItemsTotal)
+	quote.Total = quote.ItemsTotal + quote.ShippingFee
+	return quote, nil
+}
+
+// shippingFee 商品总额达到包邮门槛时免运费
+func shippingFee(itemsTotal money.Money) money.Money {
+	if pricing.FreeShippingThreshold > 0 && itemsTotal >= pricing.FreeShippingThreshold {
+		return 0
+	}
+	return pricing.ShippingFee
+}
+
+// QuoteHandler 返回订单报价，不创建订单，请求体与下单相同，total 和 address 可以省略
+func QuoteHandler(ctx context.Context, c *app.RequestContext) {
+	var req OrderRequest
+	if err := c.Bind(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   fmt.Sprintf("failed to bind request: %v", err),
+			"status": 400,
+		})
+		return
+	}
+	if len(req.Orders) == 0 {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "orders is required",
+			"status": 400,
+		})
+		return
+	}
+
+	quote, err := buildQuote(DB, req.Orders)
+	if err != nil {
+		respondQuoteError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   quote,
+	})
+}
+
+// respondQuoteError 订单项不合法时返回 400，其他错误返回 500
+func respondQuoteError(c *app.RequestContext, err error) {
+	if errors.Is(err, errInvalidOrderItem) {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 400,
+		})
+		return
+	}
+	c.JSON(consts.StatusInternalServerError, utils.H{
+		"info":   fmt.Sprintf("database error: %v", err),
+		"status": 500,
+	})
+}
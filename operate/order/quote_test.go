@@ -0,0 +1,30 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBuildQuoteRejectsQuantityOverLimit(t *testing.T) {
+	db := openTestDB(t)
+	tests := [][]OrderItemRequest{
+		{{ProductID: "p1", Quantity: maxItemQuantity + 1}},
+		{{ProductID: "p1", Quantity: maxItemQuantity}, {ProductID: "p1", Quantity: 1}},
+		// 两项相加会回绕成 1
+		{{ProductID: "p1", Quantity: math.MaxUint}, {ProductID: "p1", Quantity: 2}},
+	}
+	for _, items := range tests {
+		if _, err := buildQuote(db, items); !errors.Is(err, errInvalidOrderItem) {
+			t.Errorf("buildQuote(%+v) = %v, want errInvalidOrderItem", items, err)
+		}
+	}
+
+	quote, err := buildQuote(db, []OrderItemRequest{{ProductID: "p1", Quantity: maxItemQuantity}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.ItemsTotal != 1000*maxItemQuantity {
+		t.Errorf("items total for %d items = %v, want %d cents", maxItemQuantity, quote.ItemsTotal, 1000*maxItemQuantity)
+	}
+}
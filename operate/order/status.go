@@ -0,0 +1,236 @@
+package order
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"log"
+	"strconv"
+	"time"
+)
+
+// transitions 订单状态机，列出每个状态允许进入的下一个状态，没有列出的状态是终态。
+// 订单状态只能通过 transition 修改
+var transitions = map[string][]string{
+	models.OrderStatusPendingPayment: {models.OrderStatusPaid, models.OrderStatusCancelled},
+	models.OrderStatusPaid:           {models.OrderStatusShipped, models.OrderStatusRefunded},
+	models.OrderStatusShipped:        {models.OrderStatusDelivered},
+	models.OrderStatusDelivered:      {models.OrderStatusCompleted, models.OrderStatusRefunded},
+}
+
+// statusTimeColumns 进入各状态时记录时间的列
+var statusTimeColumns = map[string]string{
+	models.OrderStatusPaid:      "paid_at",
+	models.OrderStatusShipped:   "shipped_at",
+	models.OrderStatusDelivered: "delivered_at",
+	models.OrderStatusCompleted: "completed_at",
+	models.OrderStatusCancelled: "cancelled_at",
+	models.OrderStatusRefunded:  "refunded_at",
+}
+
+var (
+	// errInvalidTransition 状态机不允许从当前状态进入目标状态
+	errInvalidTransition = errors.New("invalid status transition")
+	// errStatusChanged 读取订单后状态已被并发请求修改
+	errStatusChanged = errors.New("order status has changed, please retry")
+	// errOrderNotFound 订单不存在或不属于调用方
+	errOrderNotFound = errors.New("order not found")
+)
+
+// StatusRequest 定义修改订单状态的请求体，用户取消订单时 Status 不需要填写
+type StatusRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// canTransition 判断订单能否从 from 进入 to
+func canTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// validStatus 判断 status 是否为已定义的订单状态
+func validStatus(status string) bool {
+	_, ok := statusTimeColumns[status]
+	return ok || status == models.OrderStatusPendingPayment
+}
+
+// releasesStock 判断状态变更是否归还库存：取消订单或发货前退款时商品没有离开仓库
+func releasesStock(from, to string) bool {
+	return to == models.OrderStatusCancelled || (to == models.OrderStatusRefunded && from == models.OrderStatusPaid)
+}
+
+// transition 在事务 tx 中把订单改为 to 状态，记录进入时间和状态变更记录，需要时归还库存。
+// 用 status = 当前状态 作为更新条件，并发修改同一订单时只有一个请求成功，其余返回 errStatusChanged
+func transition(tx *gorm.DB, order *models.Order, to string, actorID uint, note string) error {
+	from := order.Status
+	if !canTransition(from, to) {
+		return fmt.Errorf("%w from %s to %s", errInvalidTransition, from, to)
+	}
+
+	result := tx.Model(&models.Order{}).
+		Where("order_id = ? AND status = ?", order.OrderID, from).
+		Updates(map[string]interface{}{"status": to, statusTimeColumns[to]: time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errStatusChanged
+	}
+
+	if releasesStock(from, to) {
+		var items []models.OrderItem
+		if err := tx.Where("order_id = ?", order.OrderID).Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			// 已下架的商品也归还库存，恢复上架后可以继续售卖
+			if err := tx.Unscoped().Model(&models.Product{}).
+				Where("product_id = ?", item.ProductID).
+				UpdateColumn("num", gorm.Expr("num + ?", item.Quantity)).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Create(&models.OrderEvent{
+		OrderID:    order.OrderID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorID,
+		Note:       note,
+	}).Error
+}
+
+// changeStatus 在一个事务中读取订单并修改状态，ownerID 不为 0 时只允许修改该用户的订单。
+// 成功时返回修改后的订单及其商品项和状态变更记录
+func changeStatus(db *gorm.DB, orderID, ownerID uint, to string, actorID uint, note string) (*models.Order, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("order_id = ?", orderID)
+		if ownerID != 0 {
+			query = query.Where("user_id = ?", ownerID)
+		}
+		var order models.Order
+		if err := query.First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errOrderNotFound
+			}
+			return err
+		}
+		return transition(tx, &order, to, actorID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loadOrder(db.Where("order_id = ?", orderID))
+}
+
+// bindStatusRequest 解析订单 ID 和请求体，请求体可以为空
+func bindStatusRequest(c *app.RequestContext) (uint, StatusRequest, error) {
+	var req StatusRequest
+	orderID, err := strconv.ParseUint(c.Param("order_id"), 10, 64)
+	if err != nil {
+		return 0, req, errors.New("invalid order_id")
+	}
+	if len(c.Request.Body()) > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			return 0, req, fmt.Errorf("failed to bind request: %v", err)
+		}
+	}
+	if len(req.Note) > 255 {
+		return 0, req, errors.New("note must be at most 255 characters")
+	}
+	return uint(orderID), req, nil
+}
+
+// respondStatusError 按 changeStatus 的错误类型返回响应
+func respondStatusError(c *app.RequestContext, orderID uint, err error) {
+	switch {
+	case errors.Is(err, errOrderNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   err.Error(),
+			"status": 404,
+		})
+	case errors.Is(err, errInvalidTransition), errors.Is(err, errStatusChanged):
+		c.JSON(consts.StatusConflict, utils.H{
+			"info":   err.Error(),
+			"status": 409,
+		})
+	default:
+		log.Printf("Failed to change status of order %d: %v", orderID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   fmt.Sprintf("failed to update order: %v", err),
+			"status": 500,
+		})
+	}
+}
+
+// CancelOrderHandler 用户取消自己的订单，只有待支付的订单可以取消，取消后归还库存
+func CancelOrderHandler(ctx context.Context, c *app.RequestContext) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 401,
+		})
+		return
+	}
+	orderID, req, err := bindStatusRequest(c)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 400,
+		})
+		return
+	}
+
+	order, err := changeStatus(DB, orderID, principal.UserID, models.OrderStatusCancelled, principal.UserID, req.Note)
+	if err != nil {
+		respondStatusError(c, orderID, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   order,
+	})
+}
+
+// UpdateStatusHandler 管理员推进订单状态，目标状态必须是状态机允许的下一个状态
+func UpdateStatusHandler(ctx context.Context, c *app.RequestContext) {
+	principal, _ := auth.PrincipalFrom(c)
+	orderID, req, err := bindStatusRequest(c)
+	if err == nil {
+		if _, ok := statusTimeColumns[req.Status]; !ok {
+			err = errors.New("status must be one of paid, shipped, delivered, completed, cancelled, refunded")
+		}
+	}
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 400,
+		})
+		return
+	}
+
+	order, err := changeStatus(DB, orderID, 0, req.Status, principal.UserID, req.Note)
+	if err != nil {
+		respondStatusError(c, orderID, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   order,
+	})
+}
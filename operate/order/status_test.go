@@ -0,0 +1,132 @@
+package order
+
+import (
+	"awesomeProject/models"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{models.OrderStatusPendingPayment, models.OrderStatusPaid, true},
+		{models.OrderStatusPendingPayment, models.OrderStatusCancelled, true},
+		{models.OrderStatusPendingPayment, models.OrderStatusShipped, false},
+		{models.OrderStatusPaid, models.OrderStatusShipped, true},
+		{models.OrderStatusPaid, models.OrderStatusRefunded, true},
+		{models.OrderStatusPaid, models.OrderStatusCancelled, false},
+		{models.OrderStatusShipped, models.OrderStatusDelivered, true},
+		{models.OrderStatusShipped, models.OrderStatusRefunded, false},
+		{models.OrderStatusDelivered, models.OrderStatusCompleted, true},
+		{models.OrderStatusDelivered, models.OrderStatusRefunded, true},
+		{models.OrderStatusCompleted, models.OrderStatusRefunded, false},
+		{models.OrderStatusCancelled, models.OrderStatusPaid, false},
+		{models.OrderStatusRefunded, models.OrderStatusPaid, false},
+		{models.OrderStatusPaid, models.OrderStatusPaid, false},
+	}
+	for _, tt := range tests {
+		if got := canTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("canTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPlaceOrderRecordsInitialEvent(t *testing.T) {
+	db := openTestDB(t)
+	order := newTestOrder(t, db, 1)
+	var events []models.OrderEvent
+	if err := db.Where("order_id = ?", order.OrderID).Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != models.OrderStatusPendingPayment || len(events) != 1 || events[0].ToStatus != models.OrderStatusPendingPayment {
+		t.Errorf("new order status %s with events %+v, want pending_payment with one event", order.Status, events)
+	}
+}
+
+func TestChangeStatusRecordsTimestampAndEvent(t *testing.T) {
+	db := openTestDB(t)
+	order := newTestOrder(t, db, 1)
+
+	updated, err := changeStatus(db, order.OrderID, 0, models.OrderStatusPaid, 99, "paid offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != models.OrderStatusPaid || updated.PaidAt == nil || updated.ShippedAt != nil {
+		t.Errorf("after paying: status %s, paid_at %v, shipped_at %v", updated.Status, updated.PaidAt, updated.ShippedAt)
+	}
+	if len(updated.Events) != 2 {
+		t.Fatalf("events = %+v, want 2", updated.Events)
+	}
+	event := updated.Events[1]
+	if event.FromStatus != models.OrderStatusPendingPayment || event.ToStatus != models.OrderStatusPaid || event.ActorID != 99 || event.Note != "paid offline" {
+		t.Errorf("paid event = %+v", event)
+	}
+}
+
+func TestChangeStatusRejectsInvalidTransition(t *testing.T) {
+	db := openTestDB(t)
+	order := newTestOrder(t, db, 1)
+	if _, err := changeStatus(db, order.OrderID, 0, models.OrderStatusShipped, 99, ""); !errors.Is(err, errInvalidTransition) {
+		t.Fatalf("shipping an unpaid order = %v, want errInvalidTransition", err)
+	}
+	var events int64
+	db.Model(&models.OrderEvent{}).Where("order_id = ?", order.OrderID).Count(&events)
+	if events != 1 {
+		t.Errorf("events after a rejected transition = %d, want 1", events)
+	}
+}
+
+func TestChangeStatusOnlyForOwner(t *testing.T) {
+	db := openTestDB(t)
+	order := newTestOrder(t, db, 1)
+	if _, err := changeStatus(db, order.OrderID, testUserID+1, models.OrderStatusCancelled, testUserID+1, ""); !errors.Is(err, errOrderNotFound) {
+		t.Errorf("cancelling another user's order = %v, want errOrderNotFound", err)
+	}
+}
+
+func TestTransitionDetectsConcurrentChange(t *testing.T) {
+	db := openTestDB(t)
+	order := newTestOrder(t, db, 1)
+	stale := *order
+	if _, err := changeStatus(db, order.OrderID, 0, models.OrderStatusPaid, 99, ""); err != nil {
+		t.Fatal(err)
+	}
+	// stale 仍是待支付，取消的条件更新不会命中
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return transition(tx, &stale, models.OrderStatusCancelled, testUserID, "")
+	})
+	if !errors.Is(err, errStatusChanged) {
+		t.Errorf("transition from a stale status = %v, want errStatusChanged", err)
+	}
+}
+
+func TestStockReleasedOnlyBeforeShipping(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  []string
+		stock int
+	}{
+		{"cancelled", []string{models.OrderStatusCancelled}, 5},
+		{"refunded before shipping", []string{models.OrderStatusPaid, models.OrderStatusRefunded}, 5},
+		{"refunded after delivery", []string{models.OrderStatusPaid, models.OrderStatusShipped, models.OrderStatusDelivered, models.OrderStatusRefunded}, 3},
+		{"completed", []string{models.OrderStatusPaid, models.OrderStatusShipped, models.OrderStatusDelivered, models.OrderStatusCompleted}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			order := newTestOrder(t, db, 2)
+			for _, status := range tt.path {
+				if _, err := changeStatus(db, order.OrderID, 0, status, 99, ""); err != nil {
+					t.Fatalf("changeStatus(%s): %v", status, err)
+				}
+			}
+			if got := stockOf(t, db); got != tt.stock {
+				t.Errorf("stock = %d, want %d", got, tt.stock)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// 分页大小的默认值和上限
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor 游标无法解析或与当前排序不一致
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params 定义分页参数。Cursor 不为空时使用游标分页，忽略 Page
+type Params struct {
+	Page     int
+	PageSize int
+	Cursor   *Cursor
+}
+
+// Meta 定义响应中的分页信息，NextCursor 为空表示没有下一页
+type Meta struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Cursor 定义游标分页的位置：按 Sort 排序时最后一条记录的排序值和主键
+type Cursor struct {
+	Sort  string      `json:"s"`
+	Desc  bool        `json:"d,omitempty"`
+	Value interface{} `json:"v"`
+	ID    interface{} `json:"id"`
+}
+
+// FromRequest 从查询参数 page、page_size、cursor 中读取分页参数
+func FromRequest(c *app.RequestContext) (Params, error) {
+	params := Params{Page: 1, PageSize: DefaultPageSize}
+	if page := c.Query("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return Params{}, errors.New("page must be a positive integer")
+		}
+		params.Page = n
+	}
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		n, err := strconv.Atoi(pageSize)
+		if err != nil || n < 1 || n > MaxPageSize {
+			return Params{}, fmt.Errorf("page_size must be between 1 and %d", MaxPageSize)
+		}
+		params.PageSize = n
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return Params{}, err
+		}
+		params.Cursor = &decoded
+	}
+	return params, nil
+}
+
+// Encode 把游标编码为 URL 安全的字符串
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析 Encode 生成的游标
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" || cursor.Value == nil || cursor.ID == nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Apply 给查询加上排序和分页条件，多取一条记录用于判断是否还有下一页。
+// sortColumn 为排序列，idColumn 为主键列，两者共同保证顺序稳定；游标必须由同样的 sort 和 desc 生成。
+func (p Params) Apply(db *gorm.DB, sort string, desc bool, sortColumn, idColumn string) (*gorm.DB, error) {
+	direction := "ASC"
+	op := ">"
+	if desc {
+		direction = "DESC"
+		op = "<"
+	}
+	db = db.Order(fmt.Sprintf("%s %s, %s %s", sortColumn, direction, idColumn, direction))
+
+	if p.Cursor != nil {
+		if p.Cursor.Sort != sort || p.Cursor.Desc != desc {
+			return nil, ErrInvalidCursor
+		}
+		db = db.Where(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", sortColumn, op, sortColumn, idColumn, op),
+			p.Cursor.Value, p.Cursor.Value, p.Cursor.ID,
+		)
+	} else {
+		db = db.Offset((p.Page - 1) * p.PageSize)
+	}
+	return db.Limit(p.PageSize + 1), nil
+}
+
+// Fetch 统计 query 的总条数，再按 Apply 的排序和分页条件调用 find 读取当前页，返回总条数。
+// find 在传入的查询上执行 Find 等方法，当前页同样多取一条；游标与排序不一致时返回 ErrInvalidCursor
+func (p Params) Fetch(query *gorm.DB, sort string, desc bool, sortColumn, idColumn string, find func(page *gorm.DB) error) (int64, error) {
+	// Session 之后 query 可以安全地用于计数和分页两次查询
+	query = query.Session(&gorm.Session{})
+	pageQuery, err := p.Apply(query, sort, desc, sortColumn, idColumn)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, find(pageQuery)
+}
+
+// Meta 生成分页信息。fetched 为 Apply 查询实际返回的条数，
+// last 返回当前页最后一条记录的排序值和主键，用于生成下一页游标
+func (p Params) Meta(total int64, fetched int, sort string, desc bool, last func() (value, id interface{})) Meta {
+	meta := Meta{PageSize: p.PageSize, Total: total}
+	if p.Cursor == nil {
+		meta.Page = p.Page
+	}
+	if fetched > p.PageSize {
+		value, id := last()
+		meta.NextCursor = Cursor{Sort: sort, Desc: desc, Value: value, ID: id}.Encode()
+	}
+	return meta
+}
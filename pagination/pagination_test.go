@@ -0,0 +1,188 @@
+package pagination
+
+import (
+	"awesomeProject/storage"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type testItem struct {
+	ID    uint `gorm:"primaryKey"`
+	Score int
+	Label *string
+}
+
+func openTestItems(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := storage.OpenInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testItem{}); err != nil {
+		t.Fatal(err)
+	}
+	label := func(s string) *string { return &s }
+	// Score 有重复值，Label 有 NULL，用于检查排序稳定和 NULL 处理
+	items := []testItem{
+		{ID: 1, Score: 30, Label: label("b")},
+		{ID: 2, Score: 10, Label: nil},
+		{ID: 3, Score: 20, Label: label("a")},
+		{ID: 4, Score: 10, Label: label("c")},
+		{ID: 5, Score: 20, Label: nil},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// walk 用游标逐页读取全部记录，返回读到的 ID 顺序
+func walk(t *testing.T, db *gorm.DB, sort string, desc bool, column string, value func(testItem) interface{}) []uint {
+	t.Helper()
+	var ids []uint
+	params := Params{Page: 1, PageSize: 2}
+	for pages := 0; pages < 10; pages++ {
+		query, err := params.Apply(db.Model(&testItem{}), sort, desc, column, "id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items []testItem
+		if err := query.Find(&items).Error; err != nil {
+			t.Fatal(err)
+		}
+		fetched := len(items)
+		if fetched > params.PageSize {
+			items = items[:params.PageSize]
+		}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		meta := params.Meta(5, fetched, sort, desc, func() (interface{}, interface{}) {
+			last := items[len(items)-1]
+			return value(last), last.ID
+		})
+		if meta.NextCursor == "" {
+			return ids
+		}
+		cursor, err := DecodeCursor(meta.NextCursor)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%s): %v", meta.NextCursor, err)
+		}
+		params.Cursor = &cursor
+	}
+	t.Fatal("cursor pagination did not terminate")
+	return nil
+}
+
+func TestApplyOffset(t *testing.T) {
+	db := openTestItems(t)
+	query, err := Params{Page: 2, PageSize: 2}.Apply(db.Model(&testItem{}), "score", false, "score", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []testItem
+	if err := query.Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 多取一条用于判断是否有下一页
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if !reflect.DeepEqual(ids, []uint{3, 5, 1}) {
+		t.Errorf("page 2 = %v, want [3 5 1]", ids)
+	}
+}
+
+func TestApplyCursorVisitsEveryRowOnce(t *testing.T) {
+	db := openTestItems(t)
+	score := func(item testItem) interface{} { return item.Score }
+	if got := walk(t, db, "score", false, "score", score); !reflect.DeepEqual(got, []uint{2, 4, 3, 5, 1}) {
+		t.Errorf("ascending walk = %v, want [2 4 3 5 1]", got)
+	}
+	if got := walk(t, db, "score", true, "score", score); !reflect.DeepEqual(got, []uint{1, 5, 3, 4, 2}) {
+		t.Errorf("descending walk = %v, want [1 5 3 4 2]", got)
+	}
+}
+
+func TestApplyCursorWithNullableColumn(t *testing.T) {
+	db := openTestItems(t)
+	// 可为 NULL 的列需要 COALESCE，否则游标条件会跳过 NULL 记录
+	label := func(item testItem) interface{} {
+		if item.Label == nil {
+			return ""
+		}
+		return *item.Label
+	}
+	if got := walk(t, db, "label", false, "COALESCE(label, '')", label); !reflect.DeepEqual(got, []uint{2, 5, 3, 1, 4}) {
+		t.Errorf("ascending walk = %v, want [2 5 3 1 4]", got)
+	}
+}
+
+func TestApplyRejectsCursorFromAnotherSort(t *testing.T) {
+	db := openTestItems(t)
+	for _, cursor := range []Cursor{
+		{Sort: "id", Value: 1, ID: 1},
+		{Sort: "score", Desc: true, Value: 1, ID: 1},
+	} {
+		params := Params{PageSize: 2, Cursor: &cursor}
+		if _, err := params.Apply(db.Model(&testItem{}), "score", false, "score", "id"); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Apply with cursor %+v = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	cursor := Cursor{Sort: "price", Desc: true, Value: "9.80", ID: "p1"}
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil || !reflect.DeepEqual(decoded, cursor) {
+		t.Errorf("DecodeCursor(Encode(%+v)) = %+v, %v", cursor, decoded, err)
+	}
+	for _, s := range []string{"", "not base64!", Cursor{Sort: "price"}.Encode(), "e30"} {
+		if _, err := DecodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestMeta(t *testing.T) {
+	last := func() (interface{}, interface{}) { return 10, 4 }
+	if meta := (Params{Page: 1, PageSize: 2}).Meta(5, 2, "score", false, last); meta.NextCursor != "" || meta.Page != 1 {
+		t.Errorf("Meta without an extra row = %+v, want no next cursor", meta)
+	}
+	meta := Params{Page: 1, PageSize: 2}.Meta(5, 3, "score", false, last)
+	if meta.Total != 5 || meta.NextCursor == "" {
+		t.Errorf("Meta with an extra row = %+v, want a next cursor", meta)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	db := openTestItems(t)
+	params := Params{Page: 1, PageSize: 2}
+	var items []testItem
+	total, err := params.Fetch(db.Model(&testItem{}).Where("score >= ?", 20), "score", true, "score", "id", func(page *gorm.DB) error {
+		return page.Find(&items).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if total != 3 || !reflect.DeepEqual(ids, []uint{1, 5, 3}) {
+		t.Errorf("Fetch = %d items %v, want 3 items [1 5 3]", total, ids)
+	}
+
+	params.Cursor = &Cursor{Sort: "price", Value: 1, ID: 1}
+	called := false
+	if _, err := params.Fetch(db.Model(&testItem{}), "score", true, "score", "id", func(page *gorm.DB) error {
+		called = true
+		return nil
+	}); !errors.Is(err, ErrInvalidCursor) || called {
+		t.Errorf("Fetch with a cursor from another sort = %v (find called: %v), want ErrInvalidCursor", err, called)
+	}
+}
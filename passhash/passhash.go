@@ -0,0 +1,54 @@
+package passhash
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cost 新密码使用的 bcrypt cost，库中 cost 更低的哈希会在登录时自动升级
+const Cost = 12
+
+// MaxLength bcrypt 只使用密码的前 72 个字节，更长的密码直接拒绝
+const MaxLength = 72
+
+// ErrTooLong 密码超过 MaxLength 字节
+var ErrTooLong = errors.New("password must be at most 72 bytes")
+
+// dummyHash 用户不存在时用于比较的哈希，使响应时间与用户存在时一致
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), Cost)
+
+// Hash 计算密码的 bcrypt 哈希
+func Hash(plain string) (string, error) {
+	if len(plain) > MaxLength {
+		return "", ErrTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), Cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify 校验密码是否与库中存储的值匹配。
+// 库中可能仍是升级前保存的明文，此时以常量时间比较，并通过 needsRehash 要求调用方重新哈希；
+// cost 低于 Cost 的旧哈希同样需要重新哈希。
+func Verify(stored, plain string) (ok bool, needsRehash bool) {
+	cost, err := bcrypt.Cost([]byte(stored))
+	if err != nil {
+		// 不是 bcrypt 哈希，按旧版明文处理
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(plain)) == 1
+		return ok, ok
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(plain)) != nil {
+		return false, false
+	}
+	return true, cost < Cost
+}
+
+// VerifyMissing 在用户不存在时调用，消耗与 Verify 相同的时间，避免通过响应时间枚举用户名
+func VerifyMissing(plain string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(plain))
+}
@@ -0,0 +1,53 @@
+package passhash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := Hash("secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == "secret123" {
+		t.Fatal("Hash returned the plain password")
+	}
+	if ok, needsRehash := Verify(hash, "secret123"); !ok || needsRehash {
+		t.Errorf("Verify(hash, correct) = %v, %v, want true, false", ok, needsRehash)
+	}
+	if ok, needsRehash := Verify(hash, "wrong"); ok || needsRehash {
+		t.Errorf("Verify(hash, wrong) = %v, %v, want false, false", ok, needsRehash)
+	}
+}
+
+func TestVerifyPlaintext(t *testing.T) {
+	if ok, needsRehash := Verify("secret123", "secret123"); !ok || !needsRehash {
+		t.Errorf("Verify(plain, correct) = %v, %v, want true, true", ok, needsRehash)
+	}
+	if ok, needsRehash := Verify("secret123", "secret12"); ok || needsRehash {
+		t.Errorf("Verify(plain, wrong) = %v, %v, want false, false", ok, needsRehash)
+	}
+}
+
+func TestVerifyLowCostNeedsRehash(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, needsRehash := Verify(string(hash), "secret123"); !ok || !needsRehash {
+		t.Errorf("Verify(low cost hash, correct) = %v, %v, want true, true", ok, needsRehash)
+	}
+}
+
+func TestHashRejectsLongPassword(t *testing.T) {
+	if _, err := Hash(strings.Repeat("a", MaxLength)); err != nil {
+		t.Errorf("Hash(%d bytes) error = %v", MaxLength, err)
+	}
+	if _, err := Hash(strings.Repeat("a", MaxLength+1)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Hash(%d bytes) error = %v, want ErrTooLong", MaxLength+1, err)
+	}
+}
@@ -0,0 +1,292 @@
+package admin
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"awesomeProject/searchindex"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"log"
+	"net/url"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+// ProductRequest 定义创建和更新商品的请求体，更新时未提供的字段保持不变
+type ProductRequest struct {
+	ProductID   *string      `json:"product_id"`
+	Name        *string      `json:"name"`
+	Description *string      `json:"description"`
+	Type        *string      `json:"type"`
+	Price       *money.Money `json:"price"`
+	Cover       *string      `json:"cover"`
+	PublishTime *string      `json:"publish_time"`
+	Link        *string      `json:"link"`
+	Num         *int         `json:"num"`
+}
+
+// productIDPattern 商品 ID 只允许字母、数字、下划线和连字符
+var productIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+var DB *gorm.DB
+
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// applyTo 把请求中提供的字段写入商品，返回被修改的列名
+func (req *ProductRequest) applyTo(product *models.Product) []string {
+	var columns []string
+	setString := func(column string, dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+			columns = append(columns, column)
+		}
+	}
+	setString("name", &product.Name, req.Name)
+	setString("description", &product.Description, req.Description)
+	setString("type", &product.Type, req.Type)
+	setString("cover", &product.Cover, req.Cover)
+	setString("publish_time", &product.PublishTime, req.PublishTime)
+	setString("link", &product.Link, req.Link)
+	if req.Price != nil {
+		product.Price = *req.Price
+		columns = append(columns, "price")
+	}
+	if req.Num != nil {
+		product.Num = *req.Num
+		columns = append(columns, "num")
+	}
+	return columns
+}
+
+// validateProduct 校验商品字段，返回第一个不合法的字段说明
+func validateProduct(product *models.Product) error {
+	switch {
+	case !productIDPattern.MatchString(product.ProductID):
+		return errors.New("product_id must be 1-64 letters, digits, '_' or '-'")
+	case product.Name == "" || utf8.RuneCountInString(product.Name) > 255:
+		return errors.New("name is required and must be at most 255 characters")
+	case product.Type == "" || len(product.Type) > 64:
+		return errors.New("type is required and must be at most 64 characters")
+	case product.Price < 0:
+		return errors.New("price must not be negative")
+	case product.Num < 0:
+		return errors.New("num must not be negative")
+	}
+	if product.PublishTime != "" {
+		if _, err := time.Parse("2006-01-02", product.PublishTime); err != nil {
+			return errors.New("publish_time must be formatted as YYYY-MM-DD")
+		}
+	}
+	for field, value := range map[string]string{"cover": product.Cover, "link": product.Link} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(value) > 255 {
+			return fmt.Errorf("%s must be an http(s) URL of at most 255 characters", field)
+		}
+	}
+	return nil
+}
+
+// CreateProduct 创建商品，product_id 与已有商品（包括已删除的商品）重复时返回 409
+func CreateProduct(ctx context.Context, c *app.RequestContext) {
+	var req ProductRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "Invalid request body",
+			"status": 10001,
+		})
+		return
+	}
+	var product models.Product
+	if req.ProductID != nil {
+		product.ProductID = *req.ProductID
+	}
+	req.applyTo(&product)
+	if err := validateProduct(&product); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 10001,
+		})
+		return
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Unscoped().Model(&models.Product{}).Where("product_id = ?", product.ProductID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return gorm.ErrDuplicatedKey
+		}
+		return tx.Create(&product).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(consts.StatusConflict, utils.H{
+				"info":   "product_id already exists",
+				"status": 10006,
+			})
+			return
+		}
+		log.Printf("Failed to create product %s: %v", product.ProductID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to create product",
+			"status": 10003,
+		})
+		return
+	}
+	searchindex.Upsert(product)
+
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   product,
+	})
+}
+
+// UpdateProduct 更新商品，只修改请求中提供的字段
+func UpdateProduct(ctx context.Context, c *app.RequestContext) {
+	productID := c.Param("product_id")
+	var req ProductRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "Invalid request body",
+			"status": 10001,
+		})
+		return
+	}
+	if req.ProductID != nil && *req.ProductID != productID {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "product_id cannot be changed",
+			"status": 10001,
+		})
+		return
+	}
+
+	var product models.Product
+	if err := DB.Where("product_id = ?", productID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{
+				"info":   "Product not found",
+				"status": 10002,
+			})
+		} else {
+			c.JSON(consts.StatusInternalServerError, utils.H{
+				"info":   "Database query error",
+				"status": 10003,
+			})
+		}
+		return
+	}
+	columns := req.applyTo(&product)
+	if err := validateProduct(&product); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 10001,
+		})
+		return
+	}
+
+	if len(columns) > 0 {
+		// 只更新提供的列，避免覆盖评论数等由其他接口维护的字段；updated_at 用于搜索索引增量同步
+		columns = append(columns, "updated_at")
+		if err := DB.Model(&product).Select(columns).Updates(&product).Error; err != nil {
+			log.Printf("Failed to update product %s: %v", productID, err)
+			c.JSON(consts.StatusInternalServerError, utils.H{
+				"info":   "Failed to update product",
+				"status": 10003,
+			})
+			return
+		}
+		searchindex.Upsert(product)
+	}
+
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+		"data":   product,
+	})
+}
+
+// DeleteProduct 软删除商品，已有订单和评论中的商品记录保留
+func DeleteProduct(ctx context.Context, c *app.RequestContext) {
+	productID := c.Param("product_id")
+	result := DB.Where("product_id = ?", productID).Delete(&models.Product{})
+	if result.Error != nil {
+		log.Printf("Failed to delete product %s: %v", productID, result.Error)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to delete product",
+			"status": 10003,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Product not found",
+			"status": 10002,
+		})
+		return
+	}
+	searchindex.Remove(productID)
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+	})
+}
+
+// RestoreProduct 恢复已软删除的商品
+func RestoreProduct(ctx context.Context, c *app.RequestContext) {
+	productID := c.Param("product_id")
+	result := DB.Unscoped().Model(&models.Product{}).
+		Where("product_id = ? AND deleted_at IS NOT NULL", productID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		log.Printf("Failed to restore product %s: %v", productID, result.Error)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to restore product",
+			"status": 10003,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Deleted product not found",
+			"status": 10002,
+		})
+		return
+	}
+	var product models.Product
+	if err := DB.Where("product_id = ?", productID).First(&product).Error; err != nil {
+		log.Printf("Failed to reload restored product %s: %v", productID, err)
+	} else {
+		searchindex.Upsert(product)
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+	})
+}
+
+// RegisterRoutes 注册商品管理路由，只有管理员可以访问
+func RegisterRoutes(r *server.Hertz) {
+	g := r.Group("/admin/product", auth.JWTAuthorization(), auth.RequireRole(models.RoleAdmin))
+	g.POST("", CreateProduct)
+	g.PUT("/:product_id", UpdateProduct)
+	g.DELETE("/:product_id", DeleteProduct)
+	g.POST("/:product_id/restore", RestoreProduct)
+}
@@ -0,0 +1,80 @@
+package bytype
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/product/cart"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// ProductListResponse 定义商品列表响应结构体
+type ProductListResponse struct {
+	Status int    `json:"status"`
+	Info   string `json:"info"`
+	Data   struct {
+		Products []models.Product `json:"products"`
+	} `json:"data"`
+}
+
+// ProductInfoResponse 定义获取单个商品信息的响应结构体
+type ProductInfoResponse struct {
+	Status int            `json:"status"`
+	Info   string         `json:"info"`
+	Data   models.Product `json:"data"`
+}
+
+var DB *gorm.DB
+
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// ListProductsByType 按类型获取商品列表
+func ListProductsByType(ctx context.Context, c *app.RequestContext) {
+	productType := c.Param("type")
+	if productType == "" {
+		productType = c.Query("type")
+	}
+	if productType == "" {
+		c.JSON(http.StatusBadRequest, ProductListResponse{
+			Status: 10001,
+			Info:   "type is required",
+		})
+		return
+	}
+	var products []models.Product
+	result := DB.Where("type =?", productType).Find(&products)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, ProductListResponse{
+			Status: 10002,
+			Info:   "Failed to query product list",
+		})
+		return
+	}
+	if err := cart.MarkAdded(c, products); err != nil {
+		c.JSON(http.StatusInternalServerError, ProductListResponse{
+			Status: 10002,
+			Info:   "Failed to query cart items",
+		})
+		return
+	}
+	resp := ProductListResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: struct {
+			Products []models.Product `json:"products"`
+		}{Products: products},
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RegisterRoutes 注册按类型获取商品列表路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/product/:type", auth.OptionalAuth(), ListProductsByType)
+}
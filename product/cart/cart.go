@@ -1,38 +1,20 @@
-package main
+package cart
 
 import (
-	"bytes"
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/money"
 	"context"
-	"fmt"
+	"crypto/hmac"
+	"crypto/sha256"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"github.com/dgrijalva/jwt-go"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"log"
-	"strconv"
 )
 
-// 定义购物车结构体
-type Cart struct {
-	gorm.Model
-	UserID    uint   `gorm:"not null"`
-	ProductID string `gorm:"not null"`
-}
-
-// 定义商品结构体
-type Product struct {
-	ProductID string  `gorm:"type:varchar(255);index"`
-	Name      string  `json:"name"`
-	Type      string  `json:"type"`
-	Price     float64 `json:"price"`
-	Cover     string  `json:"cover"`
-	Link      string  `json:"link"`
-	Num       int     `json:"num"`
-}
-
 // 定义响应结构体
 type CartProductsResponse struct {
 	Status int      `json:"status"`
@@ -40,143 +22,143 @@ type CartProductsResponse struct {
 	Data   CartData `json:"data"`
 }
 
+// CartData 定义购物车数据，Account 为全部商品小计之和
 type CartData struct {
-	Products []Product `json:"products"`
-	Account  int       `json:"account"`
+	Products []CartLine  `json:"products"`
+	Account  money.Money `json:"account"`
+}
+
+// CartLine 定义购物车中的一种商品，商品字段与数量、小计平铺在同一层
+type CartLine struct {
+	models.Product
+	Quantity uint        `json:"quantity"`
+	Subtotal money.Money `json:"subtotal"`
 }
 
-// 定义验证 JWT Token 的密钥
-var jwtKey = []byte("your_secret_key")
 var DB *gorm.DB
 
-// 验证 JWT Token 并解析用户名
-func validateAndParseUsername(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
-	if err != nil {
-		return "", err
-	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		username, ok := claims["sub"].(string)
-		if !ok {
-			return "", fmt.Errorf("username claim not found in token")
-		}
-		return username, nil
-	}
-	return "", fmt.Errorf("invalid token")
+// InitDB 注入共享的数据库连接，并从 JWT 密钥派生签名游客 cookie 的密钥
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	mac := hmac.New(sha256.New, []byte(cfg.JWT.Secret))
+	mac.Write([]byte("guest-cart"))
+	guestKey = mac.Sum(nil)
+	secureCookie = cfg.Env != config.EnvDev
+	return nil
 }
 
-// JWTAuthorization 中间件用于验证 JWT Token 并解析用户名
-func JWTAuthorization() app.HandlerFunc {
-	return func(ctx context.Context, c *app.RequestContext) {
-		authHeader := c.GetHeader("Authorization")
-		if len(authHeader) < 7 || !bytes.Equal(authHeader[:7], []byte("Bearer ")) {
-			c.JSON(consts.StatusUnauthorized, utils.H{
-				"info":   "Invalid token format",
-				"status": 10005,
-			})
-			return
-		}
-		tokenString := authHeader[7:]
-		username, err := validateAndParseUsername(string(tokenString))
-		if err != nil {
-			c.JSON(consts.StatusUnauthorized, utils.H{
-				"info":   "Unauthorized",
-				"status": 10005,
-			})
-			return
-		}
-		c.Set("username", username) // 将用户名存储到上下文
-		c.Next(ctx)
+// MarkAdded 根据调用方的购物车设置商品的 IsAddedCart，没有购物车的匿名请求全部为 false。
+// 路由需要使用 auth.JWTAuthorization 或 auth.OptionalAuth 中间件。
+func MarkAdded(c *app.RequestContext, products []models.Product) error {
+	for i := range products {
+		products[i].IsAddedCart = false
+	}
+	o, ok := ownerOf(c)
+	if !ok || len(products) == 0 {
+		return nil
 	}
-}
 
-// 初始化数据库连接
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ProductID
 	}
-	// 自动迁移表结构
-	err = DB.AutoMigrate(&Cart{}, &Product{})
-	if err != nil {
-		log.Printf("Failed to migrate database table: %v\n", err)
-		return fmt.Errorf("failed to migrate database table: %w", err)
+	var added []string
+	if err := o.query(DB).
+		Where("product_id IN ?", productIDs).
+		Distinct().
+		Pluck("product_id", &added).Error; err != nil {
+		return err
+	}
+	inCart := make(map[string]bool, len(added))
+	for _, id := range added {
+		inCart[id] = true
+	}
+	for i := range products {
+		products[i].IsAddedCart = inCart[products[i].ProductID]
 	}
 	return nil
 }
 
-func main() {
-	if err := InitDB(); err != nil {
-		log.Printf("Database initialization failed: %v\n", err)
-		return
-	}
-	fmt.Println("Database initialized successfully.")
+// Item 定义购物车中的一行，用户购物车和游客购物车共用
+type Item struct {
+	ProductID string
+	Quantity  uint
+}
 
-	h := server.New(server.WithHostPorts("127.0.0.1:8008"))
+// availableLines 读取购物车行对应的商品，返回在售商品的行和已下架商品的 ID。
+// 购物车展示和结算共用这一规则，保证两者计价的是同一组商品
+func availableLines(tx *gorm.DB, items []Item) ([]CartLine, []string, error) {
+	lines := make([]CartLine, 0, len(items))
+	if len(items) == 0 {
+		return lines, nil, nil
+	}
+	productIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	var products []models.Product
+	if err := tx.Where("product_id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]models.Product, len(products))
+	for _, product := range products {
+		product.IsAddedCart = true
+		byID[product.ProductID] = product
+	}
 
-	// 获取购物车商品列表接口
-	h.GET("/product/cart", JWTAuthorization(), func(ctx context.Context, c *app.RequestContext) {
-		USERidStr := c.Param("user_id")
-		if USERidStr == "" {
-			USERidStr = c.Query("user_id")
-		}
-		userid, err := strconv.Atoi(USERidStr)
-		if err != nil {
-			c.JSON(consts.StatusBadRequest, utils.H{
-				"info":   "Invalid id parameter",
-				"status": 10001,
-			})
-			return
+	var unavailable []string
+	for _, item := range items {
+		product, ok := byID[item.ProductID]
+		if !ok {
+			unavailable = append(unavailable, item.ProductID)
+			continue
 		}
+		lines = append(lines, CartLine{Product: product, Quantity: item.Quantity, Subtotal: product.Price.Mul(item.Quantity)})
+	}
+	return lines, unavailable, nil
+}
 
-		var cartItems []Cart
-		result := DB.Where("user_id =?", userid).Find(&cartItems)
-		if result.Error != nil {
+// GetCartProducts 获取调用方的购物车商品列表，登录用户以 token 为准，匿名请求返回游客购物车
+func GetCartProducts(ctx context.Context, c *app.RequestContext) {
+	var cartItems []Item
+	if o, ok := ownerOf(c); ok {
+		if err := o.query(DB).Select("product_id", "quantity").Order("id").Scan(&cartItems).Error; err != nil {
 			c.JSON(consts.StatusInternalServerError, utils.H{
 				"info":   "Failed to query cart items",
 				"status": 10002,
 			})
 			return
 		}
+	}
 
-		var products []Product
-		productIDs := make([]string, 0, len(cartItems))
-		for _, cartItem := range cartItems {
-			productIDs = append(productIDs, cartItem.ProductID)
-		}
-
-		if len(productIDs) > 0 {
-			result = DB.Table("products").Where("product_id IN?", productIDs).Find(&products)
-			if result.Error != nil {
-				c.JSON(consts.StatusInternalServerError, utils.H{
-					"info":   "Failed to query product details",
-					"status": 10002,
-				})
-				return
-			}
-		}
-
-		account := 0
-		for _, product := range products {
-			account += int(product.Price) // 累加价格作为金额
-		}
-
-		resp := CartProductsResponse{
-			Status: 10000,
-			Info:   "success",
-			Data: CartData{
-				Products: products,
-				Account:  account,
-			},
-		}
-		c.JSON(consts.StatusOK, resp)
-	})
+	lines, _, err := availableLines(DB, cartItems)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to query product details",
+			"status": 10002,
+		})
+		return
+	}
+	var account money.Money
+	for _, line := range lines {
+		account += line.Subtotal
+	}
 
-	if err := h.Run(); err != nil {
-		log.Fatal(err)
+	resp := CartProductsResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: CartData{
+			Products: lines,
+			Account:  account,
+		},
 	}
+	c.JSON(consts.StatusOK, resp)
+}
+
+// RegisterRoutes 注册购物车路由，没有携带 token 时操作游客购物车，token 无效时返回 401
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/product/cart", auth.AuthOrGuest(), GetCartProducts)
+	r.PUT("/product/cart/:product_id", auth.AuthOrGuest(), SetQuantity)
+	r.DELETE("/product/cart/:product_id", auth.AuthOrGuest(), RemoveItem)
+	r.DELETE("/product/cart", auth.AuthOrGuest(), ClearCart)
 }
@@ -0,0 +1,194 @@
+package cart
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/models"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+	"time"
+)
+
+// guestCookieName 保存游客 ID 的 cookie 名
+const guestCookieName = "guest_cart"
+
+// guestCookieMaxAge 游客 cookie 的有效期，每次加入商品时续期
+const guestCookieMaxAge = 30 * 24 * time.Hour
+
+var (
+	// guestKey 签名游客 ID 的密钥，由 JWT 密钥派生
+	guestKey []byte
+	// secureCookie 非开发环境只通过 HTTPS 发送游客 cookie
+	secureCookie bool
+)
+
+// owner 定义购物车的所有者，登录用户按 userID，游客按 guestID，两者只有一个有值
+type owner struct {
+	userID  uint
+	guestID string
+}
+
+// query 返回限定在所有者购物车上的查询
+func (o owner) query(tx *gorm.DB) *gorm.DB {
+	if o.guestID != "" {
+		return tx.Model(&models.GuestCartItem{}).Where("guest_id = ?", o.guestID)
+	}
+	return tx.Model(&models.Cart{}).Where("user_id = ?", o.userID)
+}
+
+// model 返回所有者购物车对应的表模型
+func (o owner) model() interface{} {
+	if o.guestID != "" {
+		return &models.GuestCartItem{}
+	}
+	return &models.Cart{}
+}
+
+func (o owner) String() string {
+	if o.guestID != "" {
+		return "guest " + o.guestID
+	}
+	return fmt.Sprintf("user %d", o.userID)
+}
+
+// signGuestID 返回“游客 ID.签名”形式的 cookie 值
+func signGuestID(id string) string {
+	mac := hmac.New(sha256.New, guestKey)
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyGuestCookie 校验 cookie 的签名，返回其中的游客 ID
+func verifyGuestCookie(value string) (string, bool) {
+	id, _, ok := strings.Cut(value, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(signGuestID(id)), []byte(value)) {
+		return "", false
+	}
+	return id, true
+}
+
+// setGuestCookie 写入游客 cookie，maxAge 小于 0 时删除 cookie。
+// RequestContext.SetCookie 会忽略负数的 maxAge，删除时需要设置过期时间
+func setGuestCookie(c *app.RequestContext, value string, maxAge time.Duration) {
+	cookie := protocol.AcquireCookie()
+	defer protocol.ReleaseCookie(cookie)
+	cookie.SetKey(guestCookieName)
+	cookie.SetValue(value)
+	cookie.SetPath("/")
+	cookie.SetHTTPOnly(true)
+	cookie.SetSecure(secureCookie)
+	cookie.SetSameSite(protocol.CookieSameSiteLaxMode)
+	if maxAge < 0 {
+		cookie.SetExpire(protocol.CookieExpireDelete)
+	} else {
+		cookie.SetMaxAge(int(maxAge.Seconds()))
+	}
+	c.Response.Header.SetCookie(cookie)
+}
+
+// ownerOf 返回请求的购物车所有者：登录用户优先，其次是 cookie 中的游客；都没有时 ok 为 false
+func ownerOf(c *app.RequestContext) (owner, bool) {
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		return owner{userID: principal.UserID}, true
+	}
+	if id, ok := verifyGuestCookie(string(c.Cookie(guestCookieName))); ok {
+		return owner{guestID: id}, true
+	}
+	return owner{}, false
+}
+
+// ensureOwner 与 ownerOf 相同，但请求没有有效的游客 cookie 时生成新的游客 ID。
+// 不写 cookie，调用方在商品成功加入购物车后再调用 renewGuestCookie，失败的请求不会产生游客
+func ensureOwner(c *app.RequestContext) (owner, error) {
+	if o, ok := ownerOf(c); ok {
+		return o, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return owner{}, fmt.Errorf("failed to generate guest id: %w", err)
+	}
+	return owner{guestID: hex.EncodeToString(b)}, nil
+}
+
+// renewGuestCookie 写入或续期游客 cookie，登录用户不做任何事
+func renewGuestCookie(c *app.RequestContext, o owner) {
+	if o.guestID != "" {
+		setGuestCookie(c, signGuestID(o.guestID), guestCookieMaxAge)
+	}
+}
+
+// purgeStaleGuests 删除 guestCookieMaxAge 内没有加入过商品的游客购物车，这些游客的 cookie 已经过期
+func purgeStaleGuests() (int64, error) {
+	var stale []string
+	if err := DB.Model(&models.GuestCartItem{}).
+		Group("guest_id").
+		Having("MAX(updated_at) < ?", time.Now().Add(-guestCookieMaxAge)).
+		Pluck("guest_id", &stale).Error; err != nil {
+		return 0, err
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+	result := DB.Where("guest_id IN ?", stale).Delete(&models.GuestCartItem{})
+	return result.RowsAffected, result.Error
+}
+
+// StartGuestCartGC 启动后台任务，每隔 interval 清理一次过期的游客购物车，返回停止函数
+func StartGuestCartGC(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n, err := purgeStaleGuests()
+				if err != nil {
+					log.Printf("failed to purge guest carts: %v", err)
+				} else if n > 0 {
+					log.Printf("purged %d stale guest cart items", n)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// MergeGuestCart 在登录后把请求 cookie 中的游客购物车合并到用户购物车，并删除游客 cookie。
+// 同一商品的数量相加，超过库存的部分和已下架的商品被丢弃；请求没有游客 cookie 时不做任何事。
+func MergeGuestCart(c *app.RequestContext, userID uint) error {
+	guestID, ok := verifyGuestCookie(string(c.Cookie(guestCookieName)))
+	if !ok {
+		return nil
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var items []models.GuestCartItem
+		if err := tx.Where("guest_id = ?", guestID).Order("id").Find(&items).Error; err != nil {
+			return err
+		}
+		user := owner{userID: userID}
+		for _, item := range items {
+			err := addItem(tx, user, item.ProductID, item.Quantity, true)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrInsufficientStock) {
+				return err
+			}
+		}
+		return tx.Where("guest_id = ?", guestID).Delete(&models.GuestCartItem{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to merge guest cart %s into user %d: %w", guestID, userID, err)
+	}
+	setGuestCookie(c, "", -1)
+	return nil
+}
@@ -0,0 +1,157 @@
+package cart
+
+import (
+	"awesomeProject/config"
+	"awesomeProject/migration"
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"awesomeProject/storage"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+// openTestDB 返回执行过全部迁移的内存数据库，其中有用户 bob，
+// 商品 p1 库存 5、p2 库存 3，以及已下架的 p3
+func openTestDB(t *testing.T) (*gorm.DB, models.User) {
+	t.Helper()
+	db, err := storage.OpenInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitDB(db, config.Default()); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Username: "bob", Password: "secret123"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	products := []models.Product{
+		{ProductID: "p1", Name: "book", Price: money.FromCents(1000), Num: 5},
+		{ProductID: "p2", Name: "pen", Price: money.FromCents(200), Num: 3},
+		{ProductID: "p3", Name: "delisted", Price: money.FromCents(500), Num: 9},
+	}
+	if err := db.Create(&products).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&products[2]).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, user
+}
+
+// guestRequest 返回携带 cookie 值 value 的请求
+func guestRequest(value string) *app.RequestContext {
+	c := app.NewContext(0)
+	c.Request.Header.SetCookie(guestCookieName, value)
+	return c
+}
+
+func quantities(t *testing.T, query *gorm.DB) map[string]uint {
+	t.Helper()
+	var rows []struct {
+		ProductID string
+		Quantity  uint
+	}
+	if err := query.Select("product_id", "quantity").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]uint, len(rows))
+	for _, row := range rows {
+		got[row.ProductID] = row.Quantity
+	}
+	return got
+}
+
+func TestGuestCookieSignature(t *testing.T) {
+	cfg := config.Default()
+	if err := InitDB(nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	value := signGuestID("guest1")
+	if id, ok := verifyGuestCookie(value); !ok || id != "guest1" {
+		t.Fatalf("verifyGuestCookie(signed) = %q, %v", id, ok)
+	}
+
+	_, sig, _ := strings.Cut(value, ".")
+	for name, tampered := range map[string]string{
+		"other id":      "guest2." + sig,
+		"bad signature": "guest1." + strings.Repeat("A", len(sig)),
+		"no signature":  "guest1",
+		"empty id":      "." + sig,
+		"empty":         "",
+	} {
+		if id, ok := verifyGuestCookie(tampered); ok {
+			t.Errorf("%s: verifyGuestCookie(%q) = %q, true", name, tampered, id)
+		}
+	}
+
+	// 换了 JWT 密钥之后，旧密钥签名的 cookie 失效
+	cfg.JWT.Secret = "another secret"
+	if err := InitDB(nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := verifyGuestCookie(value); ok {
+		t.Error("cookie signed with the old key is still accepted")
+	}
+}
+
+func TestMergeGuestCart(t *testing.T) {
+	db, user := openTestDB(t)
+	guest := owner{guestID: "guest1"}
+	for _, item := range []models.GuestCartItem{
+		{GuestID: guest.guestID, ProductID: "p1", Quantity: 2},
+		{GuestID: guest.guestID, ProductID: "p2", Quantity: 3},
+		{GuestID: guest.guestID, ProductID: "p3", Quantity: 1},
+	} {
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&models.Cart{UserID: user.ID, ProductID: "p2", Quantity: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	c := guestRequest(signGuestID(guest.guestID))
+	if err := MergeGuestCart(c, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// p2 合并后超过库存，只加到库存数量；已下架的 p3 被丢弃
+	got := quantities(t, owner{userID: user.ID}.query(db))
+	if len(got) != 2 || got["p1"] != 2 || got["p2"] != 3 {
+		t.Errorf("user cart after merge = %v, want p1:2 p2:3", got)
+	}
+	if left := quantities(t, guest.query(db)); len(left) != 0 {
+		t.Errorf("guest cart after merge = %v, want empty", left)
+	}
+
+	cookie := protocol.AcquireCookie()
+	defer protocol.ReleaseCookie(cookie)
+	cookie.SetKey(guestCookieName)
+	if !c.Response.Header.Cookie(cookie) || !cookie.Expire().Equal(protocol.CookieExpireDelete) {
+		t.Error("guest cookie was not deleted after merge")
+	}
+}
+
+func TestMergeGuestCartIgnoresInvalidCookie(t *testing.T) {
+	db, user := openTestDB(t)
+	if err := db.Create(&models.GuestCartItem{GuestID: "guest1", ProductID: "p1", Quantity: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MergeGuestCart(guestRequest("guest1.forged"), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := quantities(t, owner{userID: user.ID}.query(db)); len(got) != 0 {
+		t.Errorf("user cart = %v, want empty", got)
+	}
+	if got := quantities(t, owner{guestID: "guest1"}.query(db)); got["p1"] != 2 {
+		t.Errorf("guest cart = %v, want p1:2 untouched", got)
+	}
+}
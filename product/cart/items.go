@@ -0,0 +1,264 @@
+package cart
+
+import (
+	"awesomeProject/models"
+	"context"
+	"errors"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"strconv"
+	"time"
+)
+
+// ErrInsufficientStock 购物车中的数量超过商品库存
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// errNotInCart 购物车中没有该商品
+var errNotInCart = errors.New("product not in cart")
+
+// ParseQuantity 读取表单中的 quantity，未提供时返回 defaultQuantity，数量必须为正整数
+func ParseQuantity(c *app.RequestContext, defaultQuantity uint) (uint, error) {
+	value := c.PostForm("quantity")
+	if value == "" {
+		if defaultQuantity == 0 {
+			return 0, errors.New("quantity is required")
+		}
+		return defaultQuantity, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || n == 0 {
+		return 0, errors.New("quantity must be a positive integer")
+	}
+	return uint(n), nil
+}
+
+// AddToCart 把商品加入调用方的购物车：登录用户加入用户购物车，匿名请求加入游客购物车，
+// 没有游客 cookie 时创建新游客。购物车中已有该商品时累加数量，并把商品的累计加购次数加一。
+// 商品不存在时返回 gorm.ErrRecordNotFound，累加后超过库存时返回 ErrInsufficientStock。
+func AddToCart(c *app.RequestContext, productID string, quantity uint) error {
+	o, err := ensureOwner(c)
+	if err != nil {
+		return err
+	}
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := addItem(tx, o, productID, quantity, false); err != nil {
+			return err
+		}
+		// 只改计数，不更新 updated_at，避免触发搜索索引同步
+		return tx.Model(&models.Product{}).Where("product_id = ?", productID).
+			UpdateColumn("cart_adds", gorm.Expr("cart_adds + 1")).Error
+	})
+	if err != nil {
+		return err
+	}
+	renewGuestCookie(c, o)
+	return nil
+}
+
+// addItem 把商品加入 o 的购物车并累加数量。
+// 超过库存时 capAtStock 为 false 返回 ErrInsufficientStock，为 true 则只加到库存数量为止，已达到库存时仍返回 ErrInsufficientStock。
+func addItem(db *gorm.DB, o owner, productID string, quantity uint, capAtStock bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Where("product_id = ?", productID).First(&product).Error; err != nil {
+			return err
+		}
+		var current []uint
+		if err := o.query(tx).Where("product_id = ?", productID).Pluck("quantity", &current).Error; err != nil {
+			return err
+		}
+		inCart := uint(0)
+		if len(current) > 0 {
+			inCart = current[0]
+		}
+		stock := uint(0)
+		if product.Num > 0 {
+			stock = uint(product.Num)
+		}
+		if inCart+quantity > stock {
+			if !capAtStock || inCart >= stock {
+				return ErrInsufficientStock
+			}
+			quantity = stock - inCart
+		}
+
+		if err := upsertItem(tx, o, productID, quantity); err != nil {
+			return err
+		}
+
+		// 上面的读取和写入之间可能有并发的加购，写入后在同一事务中按最终数量再检查一次库存。
+		// 写入会锁住这一行，并发的事务排队写入，后写入的一方读到的是两次累加后的数量
+		var total []uint
+		if err := o.query(tx).Where("product_id = ?", productID).Pluck("quantity", &total).Error; err != nil {
+			return err
+		}
+		if len(total) > 0 && total[0] > stock {
+			return ErrInsufficientStock
+		}
+		return nil
+	})
+}
+
+// upsertItem 在 o 的购物车中插入商品或累加已有行的数量
+func upsertItem(tx *gorm.DB, o owner, productID string, quantity uint) error {
+	if o.guestID != "" {
+		item := models.GuestCartItem{GuestID: o.guestID, ProductID: productID, Quantity: quantity}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "guest_id"}, {Name: "product_id"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "quantity"}, Value: gorm.Expr("guest_cart_items.quantity + ?", quantity)},
+				{Column: clause.Column{Name: "updated_at"}, Value: time.Now()},
+			},
+		}).Create(&item).Error
+	}
+
+	// 已软删除的行重新加入时数量从头计算。
+	// MySQL 按顺序执行赋值，quantity 必须在 deleted_at 之前，才能读到原来的 deleted_at
+	item := models.Cart{UserID: o.userID, ProductID: productID, Quantity: quantity}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "quantity"}, Value: gorm.Expr("CASE WHEN carts.deleted_at IS NULL THEN carts.quantity + ? ELSE ? END", quantity, quantity)},
+			{Column: clause.Column{Name: "deleted_at"}, Value: nil},
+			{Column: clause.Column{Name: "updated_at"}, Value: time.Now()},
+		},
+	}).Create(&item).Error
+}
+
+// SetQuantity 修改购物车中商品的数量，数量不能超过库存
+func SetQuantity(ctx context.Context, c *app.RequestContext) {
+	productID := c.Param("product_id")
+	quantity, err := ParseQuantity(c, 0)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 10001,
+		})
+		return
+	}
+	o, ok := ownerOf(c)
+	if !ok {
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Product not in cart",
+			"status": 10002,
+		})
+		return
+	}
+
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Where("product_id = ?", productID).First(&product).Error; err != nil {
+			return err
+		}
+		if product.Num < 0 || quantity > uint(product.Num) {
+			return ErrInsufficientStock
+		}
+		result := o.query(tx).Where("product_id = ?", productID).Update("quantity", quantity)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNotInCart
+		}
+		return nil
+	})
+	switch {
+	case err == nil:
+		c.JSON(consts.StatusOK, utils.H{
+			"info":   "success",
+			"status": 10000,
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Product not found",
+			"status": 10002,
+		})
+	case errors.Is(err, errNotInCart):
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Product not in cart",
+			"status": 10002,
+		})
+	case errors.Is(err, ErrInsufficientStock):
+		c.JSON(consts.StatusConflict, utils.H{
+			"info":   "Insufficient stock",
+			"status": 10006,
+		})
+	default:
+		log.Printf("Failed to update cart quantity for %s: %v", o, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to update cart",
+			"status": 10003,
+		})
+	}
+}
+
+// RemoveItem 从购物车中移除商品
+func RemoveItem(ctx context.Context, c *app.RequestContext) {
+	productID := c.Param("product_id")
+	o, ok := ownerOf(c)
+	if !ok {
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Product not in cart",
+			"status": 10002,
+		})
+		return
+	}
+	result := o.query(DB).Where("product_id = ?", productID).Delete(o.model())
+	if result.Error != nil {
+		log.Printf("Failed to remove %s from cart of %s: %v", productID, o, result.Error)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to update cart",
+			"status": 10003,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(consts.StatusNotFound, utils.H{
+			"info":   "Product not in cart",
+			"status": 10002,
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+	})
+}
+
+// ClearCart 清空购物车
+func ClearCart(ctx context.Context, c *app.RequestContext) {
+	if o, ok := ownerOf(c); ok {
+		if err := o.query(DB).Delete(o.model()).Error; err != nil {
+			log.Printf("Failed to clear cart of %s: %v", o, err)
+			c.JSON(consts.StatusInternalServerError, utils.H{
+				"info":   "Failed to update cart",
+				"status": 10003,
+			})
+			return
+		}
+	}
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+	})
+}
+
+// UserLines 在事务 tx 中读取用户购物车，按加入顺序返回在售商品的行和已下架商品的 ID，规则与 GetCartProducts 相同
+func UserLines(tx *gorm.DB, userID uint) ([]CartLine, []string, error) {
+	var items []Item
+	o := owner{userID: userID}
+	if err := o.query(tx).Select("product_id", "quantity").Order("id").Scan(&items).Error; err != nil {
+		return nil, nil, err
+	}
+	return availableLines(tx, items)
+}
+
+// RemoveUserItems 在事务 tx 中从用户购物车删除指定商品，用于结算后清空已下单和已下架的商品
+func RemoveUserItems(tx *gorm.DB, userID uint, productIDs []string) error {
+	o := owner{userID: userID}
+	return o.query(tx).Where("product_id IN ?", productIDs).Delete(o.model()).Error
+}
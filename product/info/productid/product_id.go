@@ -0,0 +1,80 @@
+package productid
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/product/cart"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// ProductListResponse 定义商品列表响应结构体
+type ProductListResponse struct {
+	Status int    `json:"status"`
+	Info   string `json:"info"`
+	Data   struct {
+		Products []models.Product `json:"products"`
+	} `json:"data"`
+}
+
+// ProductInfoResponse 定义获取单个商品信息的响应结构体
+type ProductInfoResponse struct {
+	Status int            `json:"status"`
+	Info   string         `json:"info"`
+	Data   models.Product `json:"data"`
+}
+
+var DB *gorm.DB
+
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// GetProductInfo 获取单个商品信息
+func GetProductInfo(ctx context.Context, c *app.RequestContext) {
+	productId := c.Param("product_id")
+	if productId == "" {
+		productId = c.Query("product_id")
+	}
+	if productId == "" {
+		c.JSON(http.StatusBadRequest, ProductInfoResponse{
+			Status: 10001,
+			Info:   "product_id is required",
+		})
+		return
+	}
+	var product models.Product
+	result := DB.Where("product_id =?", productId).First(&product)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, ProductInfoResponse{
+			Status: 10002,
+			Info:   "Failed to query product info",
+		})
+		return
+	}
+	products := []models.Product{product}
+	if err := cart.MarkAdded(c, products); err != nil {
+		c.JSON(http.StatusInternalServerError, ProductInfoResponse{
+			Status: 10002,
+			Info:   "Failed to query cart items",
+		})
+		return
+	}
+	product = products[0]
+	resp := ProductInfoResponse{
+		Status: 10000,
+		Info:   "success",
+		Data:   product,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RegisterRoutes 注册商品详情路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/product/info/:product_id", auth.OptionalAuth(), GetProductInfo)
+}
@@ -1,77 +1,163 @@
-package main
+package list
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/money"
+	"awesomeProject/pagination"
+	"awesomeProject/product/cart"
 	"context"
-	"fmt"
+	"errors"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-// Product 定义商品结构体
-type Product struct {
-	ProductID   string  `json:"product_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Type        string  `json:"type"`
-	CommentNum  int     `json:"comment_num"`
-	Price       float64 `json:"price"`
-	IsAddedCart bool    `json:"is_addedCart"`
-	Cover       string  `json:"cover"`
-	PublishTime string  `json:"publish_time"`
-	Link        string  `json:"link"`
-}
-
 // ProductListResponse 定义商品列表响应结构体
 type ProductListResponse struct {
-	Status int    `json:"status"`
-	Info   string `json:"info"`
-	Data   struct {
-		Products []Product `json:"products"`
-	} `json:"data"`
+	Status int             `json:"status"`
+	Info   string          `json:"info"`
+	Data   ProductListData `json:"data"`
+}
+
+// ProductListData 定义商品列表数据，分页信息与商品列表平铺在同一层
+type ProductListData struct {
+	Products []models.Product `json:"products"`
+	pagination.Meta
+}
+
+// sortColumns 允许排序的字段，未指定时按 product_id 排序。
+// 旧数据的 publish_time 可能为 NULL，按空字符串排序，游标条件才不会跳过这些商品
+var sortColumns = map[string]string{
+	"product_id":   "product_id",
+	"price":        "price",
+	"publish_time": "COALESCE(publish_time, '')",
+	"comment_num":  "comment_num",
 }
 
 var DB *gorm.DB
 
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
-	}
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
 	return nil
 }
 
-func main() {
-	if err := InitDB(); err != nil {
-		fmt.Printf("Database initialization failed: %v\n", err)
+// ListProducts 分页获取商品列表。
+// 支持 page/page_size 偏移分页和 cursor 游标分页，sort 指定排序字段，order=desc 倒序，
+// min_price、max_price 按价格过滤
+func ListProducts(ctx context.Context, c *app.RequestContext) {
+	params, err := pagination.FromRequest(c)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10002,
+			"info":   err.Error(),
+		})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", "product_id")
+	sortColumn, ok := sortColumns[sort]
+	if !ok {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10002,
+			"info":   "sort must be one of product_id, price, publish_time, comment_num",
+		})
+		return
+	}
+	var desc bool
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		desc = true
+	default:
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10002,
+			"info":   "order must be asc or desc",
+		})
 		return
 	}
-	h := server.New(server.WithHostPorts("127.0.0.1:8005"))
-	// 注册 GET /product/list 路由
-	h.GET("/product/list", func(ctx context.Context, c *app.RequestContext) {
-		var products []Product
-		// 这里假设 Product 结构体与数据库表结构对应，从数据库查询数据
-		result := DB.Find(&products)
-		if result.Error != nil {
-			c.JSON(consts.StatusInternalServerError, utils.H{
-				"status": 10001,
-				"info":   "Database query error",
+
+	query := DB.Model(&models.Product{})
+	for param, cond := range map[string]string{"min_price": "price >= ?", "max_price": "price <= ?"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		price, err := money.Parse(value)
+		if err != nil || price < 0 {
+			c.JSON(consts.StatusBadRequest, utils.H{
+				"status": 10002,
+				"info":   param + " must be a non-negative number",
 			})
 			return
 		}
-		resp := ProductListResponse{
-			Status: 10000,
-			Info:   "success",
-			Data: struct {
-				Products []Product `json:"products"`
-			}{Products: products},
-		}
-		c.JSON(consts.StatusOK, resp)
+		query = query.Where(cond, price)
+	}
+
+	products := []models.Product{}
+	total, err := params.Fetch(query, sort, desc, sortColumn, "product_id", func(page *gorm.DB) error {
+		return page.Find(&products).Error
+	})
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10002,
+			"info":   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"status": 10001,
+			"info":   "Database query error",
+		})
+		return
+	}
+
+	fetched := len(products)
+	if fetched > params.PageSize {
+		products = products[:params.PageSize]
+	}
+	if err := cart.MarkAdded(c, products); err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"status": 10001,
+			"info":   "Database query error",
+		})
+		return
+	}
+	meta := params.Meta(total, fetched, sort, desc, func() (interface{}, interface{}) {
+		last := products[len(products)-1]
+		return sortValue(last, sort), last.ProductID
 	})
-	h.Spin()
+
+	resp := ProductListResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: ProductListData{
+			Products: products,
+			Meta:     meta,
+		},
+	}
+	c.JSON(consts.StatusOK, resp)
+}
+
+// sortValue 返回商品在排序字段上的取值
+func sortValue(product models.Product, sort string) interface{} {
+	switch sort {
+	case "price":
+		return product.Price.Cents()
+	case "publish_time":
+		return product.PublishTime
+	case "comment_num":
+		return product.CommentNum
+	default:
+		return product.ProductID
+	}
+}
+
+// RegisterRoutes 注册商品列表路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/product/list", auth.OptionalAuth(), ListProducts)
 }
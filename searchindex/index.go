@@ -0,0 +1,384 @@
+package searchindex
+
+import (
+	"awesomeProject/analysis"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 建立索引的字段
+const (
+	fieldName = iota
+	fieldDescription
+	fieldType
+	numFields
+)
+
+// fieldBoosts 各字段的权重，名称命中最重要
+var fieldBoosts = [numFields]float64{3, 1, 1.5}
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// 扩展词元相对原词元的权重
+const (
+	prefixWeight = 0.7
+	fuzzyWeight  = 0.5
+)
+
+// minExpandLength 词元至少要有这么多个字符才做前缀或模糊扩展
+const minExpandLength = 2
+
+// Document 定义被索引的商品字段
+type Document struct {
+	ID          string
+	Name        string
+	Description string
+	Type        string
+}
+
+// Hit 定义一条搜索结果
+type Hit struct {
+	ID    string
+	Name  string
+	Type  string
+	Score float64
+}
+
+// termFreqs 记录一个词元在文档各字段中出现的次数
+type termFreqs [numFields]int
+
+// docEntry 定义索引中的文档及其各字段长度
+type docEntry struct {
+	doc     Document
+	lengths [numFields]int
+	terms   []string
+}
+
+// Index 是内存中的倒排索引，支持并发读写
+type Index struct {
+	mu           sync.RWMutex
+	docs         map[string]*docEntry
+	postings     map[string]map[string]*termFreqs
+	totalLengths [numFields]int
+	// sortedTerms 按字典序排列的全部词元，用于前缀查找
+	sortedTerms []string
+}
+
+// New 创建空索引
+func New() *Index {
+	return &Index{
+		docs:     make(map[string]*docEntry),
+		postings: make(map[string]map[string]*termFreqs),
+	}
+}
+
+// Len 返回索引中的文档数
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Upsert 添加文档，已存在同 ID 的文档时替换
+func (ix *Index) Upsert(doc Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(doc.ID)
+
+	entry := &docEntry{doc: doc}
+	fields := [numFields]string{doc.Name, doc.Description, doc.Type}
+	for field, text := range fields {
+		tokens := analysis.Tokenize(text)
+		entry.lengths[field] = len(tokens)
+		ix.totalLengths[field] += len(tokens)
+		for _, token := range tokens {
+			docs, ok := ix.postings[token]
+			if !ok {
+				docs = make(map[string]*termFreqs)
+				ix.postings[token] = docs
+				ix.insertTerm(token)
+			}
+			freqs, ok := docs[doc.ID]
+			if !ok {
+				freqs = &termFreqs{}
+				docs[doc.ID] = freqs
+				entry.terms = append(entry.terms, token)
+			}
+			freqs[field]++
+		}
+	}
+	ix.docs[doc.ID] = entry
+}
+
+// Remove 删除文档，文档不存在时不做任何事
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+// remove 删除文档，调用方需持有写锁
+func (ix *Index) remove(id string) {
+	entry, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for field := range entry.lengths {
+		ix.totalLengths[field] -= entry.lengths[field]
+	}
+	for _, term := range entry.terms {
+		docs := ix.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.postings, term)
+			ix.deleteTerm(term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// insertTerm 把新词元插入有序词表
+func (ix *Index) insertTerm(term string) {
+	i := sort.SearchStrings(ix.sortedTerms, term)
+	ix.sortedTerms = append(ix.sortedTerms, "")
+	copy(ix.sortedTerms[i+1:], ix.sortedTerms[i:])
+	ix.sortedTerms[i] = term
+}
+
+// deleteTerm 从有序词表中删除词元
+func (ix *Index) deleteTerm(term string) {
+	i := sort.SearchStrings(ix.sortedTerms, term)
+	if i < len(ix.sortedTerms) && ix.sortedTerms[i] == term {
+		ix.sortedTerms = append(ix.sortedTerms[:i], ix.sortedTerms[i+1:]...)
+	}
+}
+
+// Search 搜索文档并按 BM25 得分从高到低返回全部命中。
+// 没有精确命中的词元依次尝试前缀扩展和编辑距离内的模糊匹配。
+func (ix *Index) Search(q string) []Hit {
+	return ix.search(q, false)
+}
+
+// Complete 用于输入联想：最后一个词元总是按前缀扩展，返回得分最高的 limit 条结果
+func (ix *Index) Complete(q string, limit int) []Hit {
+	hits := ix.search(q, true)
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// search 执行查询，prefixLast 为 true 时最后一个词元总是按前缀扩展
+func (ix *Index) search(q string, prefixLast bool) []Hit {
+	// 查询不使用中日韩单字，避免“与”这类常见字匹配出大量无关商品，见 analysis.QueryTerms
+	tokens := analysis.QueryTerms(q)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	n := float64(len(ix.docs))
+	var avgLengths [numFields]float64
+	for field := range avgLengths {
+		if n > 0 {
+			avgLengths[field] = float64(ix.totalLengths[field]) / n
+		}
+	}
+
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+	for i, token := range tokens {
+		expansions := ix.expand(token, prefixLast && i == len(tokens)-1)
+		tokenScores := make(map[string]float64)
+		for term, weight := range expansions {
+			docs := ix.postings[term]
+			df := float64(len(docs))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			for id, freqs := range docs {
+				entry := ix.docs[id]
+				var s float64
+				for field, tf := range freqs {
+					if tf == 0 {
+						continue
+					}
+					norm := 1 - bm25B
+					if avgLengths[field] > 0 {
+						norm += bm25B * float64(entry.lengths[field]) / avgLengths[field]
+					}
+					s += fieldBoosts[field] * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+				}
+				// 同一查询词元的多个扩展只取得分最高的一个
+				if s = weight * idf * s; s > tokenScores[id] {
+					tokenScores[id] = s
+				}
+			}
+		}
+		for id, s := range tokenScores {
+			scores[id] += s
+			matched[id]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, s := range scores {
+		entry := ix.docs[id]
+		// 按命中的查询词元比例打折，优先返回匹配全部词元的文档
+		s *= float64(matched[id]) / float64(len(tokens))
+		hits = append(hits, Hit{ID: id, Name: entry.doc.Name, Type: entry.doc.Type, Score: s})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// expand 返回查询词元对应的索引词元及其权重，调用方需持有读锁
+func (ix *Index) expand(token string, forcePrefix bool) map[string]float64 {
+	expansions := make(map[string]float64)
+	if _, ok := ix.postings[token]; ok {
+		expansions[token] = 1
+		if !forcePrefix {
+			return expansions
+		}
+	}
+	if utf8.RuneCountInString(token) < minExpandLength {
+		return expansions
+	}
+
+	for i := sort.SearchStrings(ix.sortedTerms, token); i < len(ix.sortedTerms); i++ {
+		term := ix.sortedTerms[i]
+		if !strings.HasPrefix(term, token) {
+			break
+		}
+		if term != token {
+			expansions[term] = prefixWeight
+		}
+	}
+	if len(expansions) > 0 {
+		return expansions
+	}
+
+	for _, term := range ix.fuzzyTerms(token) {
+		expansions[term] = fuzzyWeight
+	}
+	return expansions
+}
+
+// fuzzyTerms 返回与词元编辑距离在允许范围内的索引词元，按距离和字典序排列，调用方需持有读锁
+func (ix *Index) fuzzyTerms(token string) []string {
+	maxDistance := maxEditDistance(token)
+	if maxDistance == 0 {
+		return nil
+	}
+	type candidate struct {
+		term     string
+		distance int
+	}
+	var candidates []candidate
+	length := utf8.RuneCountInString(token)
+	for _, term := range ix.sortedTerms {
+		if d := utf8.RuneCountInString(term) - length; d > maxDistance || -d > maxDistance {
+			continue
+		}
+		if d := editDistance(token, term, maxDistance); d > 0 && d <= maxDistance {
+			candidates = append(candidates, candidate{term, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+	terms := make([]string, len(candidates))
+	for i, c := range candidates {
+		terms[i] = c.term
+	}
+	return terms
+}
+
+// Correct 返回索引中与词元最接近的词元：词元已在索引中时原样返回，
+// 否则在编辑距离最小的候选中选出现在最多文档中的一个；找不到候选时 ok 为 false。
+// prefix 为 true 时词元是某个索引词元的前缀也视为正确，用于用户还没输入完的词。
+func (ix *Index) Correct(token string, prefix bool) (string, bool) {
+	token = analysis.Normalize(token)
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if _, ok := ix.postings[token]; ok {
+		return token, true
+	}
+	if prefix {
+		i := sort.SearchStrings(ix.sortedTerms, token)
+		if i < len(ix.sortedTerms) && strings.HasPrefix(ix.sortedTerms[i], token) {
+			return token, true
+		}
+	}
+	candidates := ix.fuzzyTerms(token)
+	if len(candidates) == 0 {
+		return "", false
+	}
+	best := candidates[0]
+	distance := editDistance(token, best, maxEditDistance(token))
+	for _, term := range candidates[1:] {
+		if editDistance(token, term, maxEditDistance(token)) > distance {
+			break
+		}
+		if len(ix.postings[term]) > len(ix.postings[best]) {
+			best = term
+		}
+	}
+	return best, true
+}
+
+// maxEditDistance 短词不做模糊匹配，长词允许更多的错误
+func maxEditDistance(token string) int {
+	switch n := utf8.RuneCountInString(token); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance 计算两个词元的 Levenshtein 距离，超过 limit 时提前返回 limit+1
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+			rowMin = minInt(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
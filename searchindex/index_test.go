@@ -0,0 +1,169 @@
+package searchindex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testIndex() *Index {
+	ix := New()
+	for _, doc := range []Document{
+		{ID: "1", Name: "Pride and Prejudice", Description: "A novel by Jane Austen", Type: "book"},
+		{ID: "2", Name: "Prism Guide", Description: "Optics for beginners", Type: "book"},
+		{ID: "3", Name: "Cotton T-shirt", Description: "Plain shirt, pride month edition", Type: "clothes"},
+		{ID: "4", Name: "傲慢与偏见", Description: "简·奥斯汀的小说", Type: "book"},
+		{ID: "5", Name: "与君书", Description: "书信集", Type: "book"},
+	} {
+		ix.Upsert(doc)
+	}
+	return ix
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestSearchRanksNameMatchesFirst(t *testing.T) {
+	ix := testIndex()
+	// 名称命中的权重高于描述命中
+	if got := hitIDs(ix.Search("pride")); !reflect.DeepEqual(got, []string{"1", "3"}) {
+		t.Errorf("Search(pride) = %v, want [1 3]", got)
+	}
+}
+
+func TestSearchPrefersDocumentsMatchingAllTerms(t *testing.T) {
+	ix := testIndex()
+	got := hitIDs(ix.Search("pride novel"))
+	if len(got) == 0 || got[0] != "1" {
+		t.Errorf("Search(pride novel) = %v, want 1 first", got)
+	}
+}
+
+func TestSearchCJKIgnoresSingleCharacters(t *testing.T) {
+	ix := testIndex()
+	// “与”不能单独匹配出“与君书”
+	if got := hitIDs(ix.Search("傲慢与偏见")); !reflect.DeepEqual(got, []string{"4"}) {
+		t.Errorf("Search(傲慢与偏见) = %v, want [4]", got)
+	}
+	if got := hitIDs(ix.Search("偏见")); !reflect.DeepEqual(got, []string{"4"}) {
+		t.Errorf("Search(偏见) = %v, want [4]", got)
+	}
+}
+
+func TestSearchPrefixAndFuzzy(t *testing.T) {
+	ix := testIndex()
+	if got := hitIDs(ix.Search("prej")); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Search(prej) = %v, want [1]", got)
+	}
+	if got := hitIDs(ix.Search("prejudise")); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Search(prejudise) = %v, want [1]", got)
+	}
+	if got := ix.Search("zzzz"); len(got) != 0 {
+		t.Errorf("Search(zzzz) = %v, want no hits", hitIDs(got))
+	}
+}
+
+func TestCompleteExpandsLastToken(t *testing.T) {
+	ix := testIndex()
+	// pri 是 pride 和 prism 的前缀，精确命中的 pride 不会阻止前缀扩展
+	got := hitIDs(ix.Complete("pri", 10))
+	want := map[string]bool{"1": true, "2": true, "3": true}
+	if len(got) != len(want) {
+		t.Fatalf("Complete(pri) = %v, want documents %v", got, want)
+	}
+	for _, id := range got {
+		if !want[id] {
+			t.Errorf("Complete(pri) returned unexpected document %s", id)
+		}
+	}
+	if got := ix.Complete("pri", 1); len(got) != 1 {
+		t.Errorf("Complete(pri, 1) returned %d hits", len(got))
+	}
+}
+
+func TestUpsertReplacesAndRemoveDeletes(t *testing.T) {
+	ix := testIndex()
+	ix.Upsert(Document{ID: "2", Name: "Lens Guide", Type: "book"})
+	if got := hitIDs(ix.Search("prism")); len(got) != 0 {
+		t.Errorf("Search(prism) after replacing document 2 = %v, want no hits", got)
+	}
+	if got := hitIDs(ix.Search("lens")); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("Search(lens) = %v, want [2]", got)
+	}
+
+	ix.Remove("1")
+	ix.Remove("missing")
+	if got := hitIDs(ix.Search("prejudice")); len(got) != 0 {
+		t.Errorf("Search(prejudice) after removing document 1 = %v, want no hits", got)
+	}
+	if ix.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", ix.Len())
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	ix := testIndex()
+	tests := []struct {
+		token  string
+		prefix bool
+		want   string
+		ok     bool
+	}{
+		{"pride", false, "pride", true},
+		{"Pride", false, "pride", true},
+		{"prejudise", false, "prejudice", true},
+		{"pri", true, "pri", true},
+		{"pri", false, "", false},
+		{"zzzzzz", false, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ix.Correct(tt.token, tt.prefix)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Correct(%q, %v) = %q, %v; want %q, %v", tt.token, tt.prefix, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"kitten", "sitting", 3, 3},
+		{"book", "book", 2, 0},
+		{"偏见", "偏向", 1, 1},
+		{"abcdef", "uvwxyz", 2, 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ix := testIndex()
+	path := t.TempDir() + "/index.snapshot"
+	mark := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := ix.SaveFile(path, mark); err != nil {
+		t.Fatal(err)
+	}
+	loaded, gotMark, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotMark.Equal(mark) {
+		t.Errorf("watermark = %v, want %v", gotMark, mark)
+	}
+	for _, q := range []string{"pride", "傲慢", "prej"} {
+		if got, want := hitIDs(loaded.Search(q)), hitIDs(ix.Search(q)); !reflect.DeepEqual(got, want) {
+			t.Errorf("loaded Search(%s) = %v, want %v", q, got, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package searchindex
+
+import (
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"time"
+)
+
+// catchUpMargin 增量同步时把时间点往前多取一段，容忍多个实例之间的时钟误差
+const catchUpMargin = time.Minute
+
+var DB *gorm.DB
+
+var (
+	// index 服务使用的商品索引，只在 InitDB 中替换
+	index = New()
+	// watermark 索引与数据库最后一次同步的时间点，写入快照
+	watermark time.Time
+	// snapshotPath 快照文件路径，为空时不使用快照
+	snapshotPath string
+)
+
+// InitDB 建立商品索引。
+// 配置了快照时先加载快照，再同步快照之后变化的商品；快照不可用时从数据库全量建立。
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	snapshotPath = cfg.Search.SnapshotPath
+	if snapshotPath != "" {
+		ix, mark, err := LoadFile(snapshotPath)
+		switch {
+		case err == nil:
+			if err := catchUp(ix, mark); err == nil {
+				log.Printf("loaded search index snapshot with %d products", ix.Len())
+				return nil
+			} else {
+				log.Printf("search index snapshot is stale, rebuilding: %v", err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			log.Printf("failed to load search index snapshot, rebuilding: %v", err)
+		}
+	}
+	if err := rebuild(); err != nil {
+		return err
+	}
+	log.Printf("built search index with %d products", index.Len())
+	return SaveSnapshot()
+}
+
+// documentOf 把商品转换为索引文档
+func documentOf(product models.Product) Document {
+	return Document{
+		ID:          product.ProductID,
+		Name:        product.Name,
+		Description: product.Description,
+		Type:        product.Type,
+	}
+}
+
+// rebuild 从数据库全量建立索引
+func rebuild() error {
+	mark := time.Now()
+	ix := New()
+	var products []models.Product
+	err := DB.FindInBatches(&products, 500, func(tx *gorm.DB, batch int) error {
+		for _, product := range products {
+			ix.Upsert(documentOf(product))
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to build search index: %w", err)
+	}
+	index, watermark = ix, mark
+	return nil
+}
+
+// catchUp 把 since 之后新增、修改和删除的商品同步到快照索引。
+// 同步后商品数量与数据库不一致，说明有绕过 updated_at 的修改，返回错误以便全量重建。
+func catchUp(ix *Index, since time.Time) error {
+	mark := time.Now()
+	since = since.Add(-catchUpMargin)
+	var changed []models.Product
+	if err := DB.Unscoped().Where("updated_at >= ? OR deleted_at >= ?", since, since).Find(&changed).Error; err != nil {
+		return err
+	}
+	for _, product := range changed {
+		if product.DeletedAt.Valid {
+			ix.Remove(product.ProductID)
+		} else {
+			ix.Upsert(documentOf(product))
+		}
+	}
+
+	var count int64
+	if err := DB.Model(&models.Product{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != ix.Len() {
+		return fmt.Errorf("index has %d products, database has %d", ix.Len(), count)
+	}
+	index, watermark = ix, mark
+	return nil
+}
+
+// SaveSnapshot 把当前索引写入快照文件，未配置快照路径时不做任何事
+func SaveSnapshot() error {
+	if snapshotPath == "" {
+		return nil
+	}
+	if err := index.SaveFile(snapshotPath, watermark); err != nil {
+		return fmt.Errorf("failed to save search index snapshot: %w", err)
+	}
+	return nil
+}
+
+// Upsert 在商品创建、修改或恢复后更新索引
+func Upsert(product models.Product) {
+	index.Upsert(documentOf(product))
+}
+
+// Remove 在商品删除后从索引中移除
+func Remove(productID string) {
+	index.Remove(productID)
+}
+
+// Search 搜索商品，按相关度从高到低返回全部命中
+func Search(q string) []Hit {
+	return index.Search(q)
+}
+
+// Complete 返回输入联想结果
+func Complete(q string, limit int) []Hit {
+	return index.Complete(q, limit)
+}
+
+// Correct 返回与词元最接近的索引词元，见 Index.Correct
+func Correct(token string, prefix bool) (string, bool) {
+	return index.Correct(token, prefix)
+}
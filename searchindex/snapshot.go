@@ -0,0 +1,75 @@
+package searchindex
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion 快照格式版本，格式不兼容时递增，旧快照会被忽略
+const snapshotVersion = 1
+
+// snapshot 定义写入磁盘的索引快照。
+// 只保存原始文档，加载时重新分词建立倒排表，分词规则变化后快照依然可用。
+type snapshot struct {
+	Version   int
+	Watermark time.Time
+	Documents []Document
+}
+
+// Save 把索引中的文档写入 w，watermark 记录索引与数据库同步的时间点
+func (ix *Index) Save(w io.Writer, watermark time.Time) error {
+	ix.mu.RLock()
+	snap := snapshot{Version: snapshotVersion, Watermark: watermark, Documents: make([]Document, 0, len(ix.docs))}
+	for _, entry := range ix.docs {
+		snap.Documents = append(snap.Documents, entry.doc)
+	}
+	ix.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(&snap)
+}
+
+// Load 从 r 读取快照并建立索引，返回快照中的同步时间点
+func Load(r io.Reader) (*Index, time.Time, error) {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decode search index snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, time.Time{}, fmt.Errorf("unsupported search index snapshot version %d", snap.Version)
+	}
+	ix := New()
+	for _, doc := range snap.Documents {
+		ix.Upsert(doc)
+	}
+	return ix, snap.Watermark, nil
+}
+
+// SaveFile 把快照写入临时文件后重命名，避免进程中途退出留下不完整的快照
+func (ix *Index) SaveFile(path string, watermark time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create search index snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := ix.Save(tmp, watermark); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write search index snapshot: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile 从文件读取快照
+func LoadFile(path string) (*Index, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer f.Close()
+	return Load(f)
+}
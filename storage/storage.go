@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"awesomeProject/config"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// memoryDBSeq 为每个内存数据库生成唯一名称，保证互相隔离
+var memoryDBSeq int64
+
+// Open 根据配置选择驱动并打开数据库连接
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case config.DriverMySQL:
+		db, err := gorm.Open(mysql.Open(cfg.DSN), &gorm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect database: %w", err)
+		}
+		return db, nil
+	case config.DriverSQLite:
+		if cfg.DSN == ":memory:" {
+			return OpenInMemory()
+		}
+		return openSQLite("file:" + cfg.DSN + "?_foreign_keys=on&_busy_timeout=5000")
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// OpenInMemory 打开一个全新的内存 SQLite 数据库。
+// 每次调用得到的数据库互相独立，适合在集成测试中为每个用例准备干净的数据。
+func OpenInMemory() (*gorm.DB, error) {
+	name := fmt.Sprintf("memdb%d", atomic.AddInt64(&memoryDBSeq, 1))
+	return openSQLite("file:" + name + "?mode=memory&cache=shared&_foreign_keys=on")
+}
+
+// openSQLite 打开 SQLite 数据库。
+// SQLite 同一时刻只允许一个写入者，这里把连接池限制为单连接，避免出现 database is locked。
+func openSQLite(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", strings.SplitN(dsn, "?", 2)[0], err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sqlite connection pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
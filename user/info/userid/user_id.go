@@ -0,0 +1,89 @@
+package userid
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+)
+
+// UserInfo 定义返回的用户信息，只包含需要的字段
+type UserInfo struct {
+	Username string `json:"nickname"`
+	Email    string `json:"email"`
+}
+
+// UserInfoResponse 定义返回的用户信息响应结构体
+type UserInfoResponse struct {
+	Status int    `json:"status"`
+	Info   string `json:"info"`
+	Data   struct {
+		User UserInfo `json:"user"`
+	} `json:"data"`
+}
+
+var DB *gorm.DB
+
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
+
+// GetUserInfo 根据用户 ID 获取用户信息
+func GetUserInfo(ctx context.Context, c *app.RequestContext) {
+	// 获取用户ID
+	// 优先从路径参数获取user_id
+	userID := c.Param("user_id")
+	if userID == "" {
+		// 如果路径参数中未获取到，则从查询参数中获取
+		userID = c.Query("user_id")
+	}
+	if userID == "" {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"status": 10001,
+			"info":   "Missing user ID",
+		})
+		return
+	}
+
+	// 模拟从数据库查询用户信息，将username赋值给Nickname
+	var user UserInfo
+	result := DB.Model(&models.User{}).Select("username", "email").Where("id =?", userID).Take(&user)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(consts.StatusNotFound, utils.H{
+				"status": 10002,
+				"info":   "User not found",
+			})
+		} else {
+			c.JSON(consts.StatusInternalServerError, utils.H{
+				"status": 10003,
+				"info":   "Database query error",
+			})
+		}
+		return
+	}
+
+	// 创建返回结构体
+	resp := UserInfoResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: struct {
+			User UserInfo `json:"user"`
+		}{User: user},
+	}
+
+	// 返回JSON响应
+	c.JSON(consts.StatusOK, resp)
+}
+
+// RegisterRoutes 注册获取用户信息路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/user/info/:user_id", auth.JWTAuthorization(), GetUserInfo)
+}
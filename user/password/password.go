@@ -1,15 +1,19 @@
-package main
+package password
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/passhash"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"github.com/dgrijalva/jwt-go"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"log"
 )
 
 // 修改密码请求结构体
@@ -18,106 +22,91 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password"`
 }
 
-// User 数据库用户模型
-type User struct {
-	ID       uint   `gorm:"primaryKey"`
-	Username string `gorm:"unique;not null"`
-	Password string `gorm:"not null"`
-}
+var DB *gorm.DB
 
-// 密钥，用于 JWT 签名和验证
-var jwtKey = []byte("your_secret_key")
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	DB = db
+	return nil
+}
 
+// ChangePassword 修改当前登录用户的密码
 func ChangePassword(ctx context.Context, c *app.RequestContext) {
-	// 从请求头获取 Authorization
-	authorization := c.Request.Header.Get("Authorization")
-	if authorization == "" {
-		c.JSON(consts.StatusBadRequest, utils.H{
-			"info":   "Authorization header is required",
-			"status": 10001,
-		})
-		return
-	}
-
-	// 解析 token
-	tokenString := authorization[len("Bearer "):]
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtKey, nil
-	})
-	if err != nil || !token.Valid {
-		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Invalid token",
-			"status": 10003,
-		})
-		return
-	}
-
-	// 从 token 中获取用户名
-	claims, ok := token.Claims.(jwt.MapClaims)
+	// 从认证信息中获取当前用户
+	principal, ok := auth.PrincipalFrom(c)
 	if !ok {
 		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Invalid token claims",
-			"status": 10008,
-		})
-		return
-	}
-	username, ok := claims["sub"].(string)
-	if ok != true {
-		c.JSON(consts.StatusUnauthorized, utils.H{
-			"info":   "Username not found in token",
-			"status": 10009,
+			"info":   "Unauthorized",
+			"status": 10005,
 		})
 		return
 	}
 
 	var req ChangePasswordRequest
 	// 绑定请求参数
-	erro := c.BindAndValidate(&req)
-	fmt.Println(erro)
-	if erro != nil {
+	if err := c.BindAndValidate(&req); err != nil {
 		c.JSON(consts.StatusBadRequest, utils.H{
-			"info":   "Invalid request parameters",
+			"info":   fmt.Sprintf("Invalid request parameters: %v", err),
 			"status": 10002,
 		})
 		return
 	}
 
-	// 打开数据库连接
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
+	// 根据 token 中的用户 ID 查找用户
+	var user models.User
+	if err := DB.First(&user, principal.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{
+				"info":   "User not found",
+				"status": 10005,
+			})
+			return
+		}
+		log.Printf("failed to load user %d: %v", principal.UserID, err)
 		c.JSON(consts.StatusInternalServerError, utils.H{
-			"info":   "Database connection error",
-			"status": 10004,
+			"info":   "Failed to update password",
+			"status": 10007,
 		})
 		return
 	}
 
-	// 根据从 token 解析出的用户名查找用户
-	var user User
-	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
-		c.JSON(consts.StatusNotFound, utils.H{
-			"info":   "User not found",
-			"status": 10005,
+	// 验证旧密码
+	if ok, _ := passhash.Verify(user.Password, req.OldPassword); !ok {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   "Old password is incorrect",
+			"status": 10006,
 		})
 		return
 	}
 
-	// 验证旧密码
-	if user.Password != req.OldPassword {
+	if req.NewPassword == "" {
 		c.JSON(consts.StatusBadRequest, utils.H{
-			"info":   "Old password is incorrect",
-			"status": 10006,
+			"info":   "New password is required",
+			"status": 10002,
 		})
 		return
 	}
 
-	// 更新新密码
-	user.Password = req.NewPassword
-	if err := db.Save(&user).Error; err != nil {
+	// 更新新密码，只保存哈希
+	hashedPassword, err := passhash.Hash(req.NewPassword)
+	if err == passhash.ErrTooLong {
+		c.JSON(consts.StatusBadRequest, utils.H{
+			"info":   err.Error(),
+			"status": 10002,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("failed to hash password for user %d: %v", user.ID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to update password",
+			"status": 10007,
+		})
+		return
+	}
+	// 只写 password 一列，避免覆盖并发写入的 token_version 等字段
+	if err := DB.Model(&user).Update("password", hashedPassword).Error; err != nil {
+		log.Printf("failed to save password for user %d: %v", user.ID, err)
 		c.JSON(consts.StatusInternalServerError, utils.H{
 			"info":   "Failed to update password",
 			"status": 10007,
@@ -131,8 +120,7 @@ func ChangePassword(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
-func main() {
-	h := server.New(server.WithHostPorts("127.0.0.1:8003"))
-	h.PUT("/user/password", ChangePassword)
-	h.Spin()
+// RegisterRoutes 注册修改密码路由
+func RegisterRoutes(r *server.Hertz) {
+	r.PUT("/user/password", auth.JWTAuthorization(), ChangePassword)
 }
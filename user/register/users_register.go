@@ -1,41 +1,28 @@
-package main
+package register
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/passhash"
 	"context"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"log"
 )
 
-type User struct {
-	Username string `gorm:"uniqueIndex;not_null"`
-	Password string `gorm:"not_null"`
-}
-
 var DB *gorm.DB
 
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
-	}
-
-	// 自动迁移
-	err = db.AutoMigrate(&User{})
-	if err != nil {
-		return fmt.Errorf("failed to auto migrate: %w", err)
-	}
-
+// InitDB 注入共享的数据库连接
+func InitDB(db *gorm.DB, cfg *config.Config) error {
 	DB = db
 	return nil
 }
 
+// Register 用户注册处理函数
 func Register(ctx context.Context, c *app.RequestContext) {
 	var req struct {
 		Username string `json:"username" binding:"required"`
@@ -52,11 +39,26 @@ func Register(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusBadRequest, utils.H{"error": "用户名不能为空"})
 		return
 	}
+	if req.Password == "" {
+		c.JSON(consts.StatusBadRequest, utils.H{"error": "密码不能为空"})
+		return
+	}
+
+	// 密码只保存 bcrypt 哈希
+	hashedPassword, err := passhash.Hash(req.Password)
+	if err != nil {
+		if err == passhash.ErrTooLong {
+			c.JSON(consts.StatusBadRequest, utils.H{"error": err.Error()})
+		} else {
+			c.JSON(consts.StatusInternalServerError, utils.H{"error": err.Error()})
+		}
+		return
+	}
 
-	err := DB.Transaction(func(tx *gorm.DB) error {
+	err = DB.Transaction(func(tx *gorm.DB) error {
 		// 检查用户名是否已存在
 		var count int64
-		result := tx.Model(&User{}).Where("username = ?", req.Username).Count(&count)
+		result := tx.Model(&models.User{}).Where("username = ?", req.Username).Count(&count)
 		if result.Error != nil {
 			return result.Error
 		}
@@ -65,15 +67,16 @@ func Register(ctx context.Context, c *app.RequestContext) {
 		}
 
 		// 创建新用户
-		newUser := User{
+		newUser := models.User{
 			Username: req.Username,
-			Password: req.Password,
+			Password: hashedPassword,
 		}
 		result = tx.Create(&newUser)
 		if result.Error != nil {
 			return fmt.Errorf("failed to create user: %w", result.Error)
 		}
-		return nil
+		// 新用户默认只有 user 角色
+		return auth.AssignRole(tx, newUser.ID, models.RoleUser)
 	})
 
 	if err != nil {
@@ -90,16 +93,8 @@ func Register(ctx context.Context, c *app.RequestContext) {
 		"info":   "success",
 	})
 }
+
+// RegisterRoutes 注册用户注册路由
 func RegisterRoutes(r *server.Hertz) {
 	r.POST("/user/register", Register)
 }
-
-func main() {
-	err := InitDB()
-	if err != nil {
-		log.Fatalf("failed to initialize database: %v", err)
-	}
-	h := server.New(server.WithHostPorts("127.0.0.1:8001"))
-	RegisterRoutes(h)
-	h.Run()
-}
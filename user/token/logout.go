@@ -0,0 +1,87 @@
+package token
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/models"
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// Logout 吊销当前使用的访问 token。
+// 请求同时带上 refresh_token 时，该刷新 token 所在的 family 一并吊销。
+func Logout(ctx context.Context, c *app.RequestContext) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 10005,
+		})
+		return
+	}
+
+	if err := auth.RevokeToken(DB, principal.TokenID, principal.UserID, principal.ExpiresAt); err != nil {
+		log.Printf("failed to revoke token %s: %v", principal.TokenID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to revoke token",
+			"status": 10003,
+		})
+		return
+	}
+
+	if refreshToken := c.Query("refresh_token"); refreshToken != "" {
+		// 刷新 token 无效或属于其他用户时忽略，不影响本次退出
+		if claims, err := validateRefreshToken(refreshToken); err == nil && claims.UserID == principal.UserID {
+			var stored models.RefreshToken
+			if err := DB.Where("jti = ?", claims.Id).First(&stored).Error; err == nil {
+				if err := revokeFamily(DB, stored.FamilyID); err != nil {
+					log.Printf("failed to revoke refresh token family %s: %v", stored.FamilyID, err)
+				}
+			}
+		}
+	}
+
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+	})
+}
+
+// LogoutAll 让当前用户此前签发的全部访问 token 和刷新 token 失效
+func LogoutAll(ctx context.Context, c *app.RequestContext) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{
+			"info":   "Unauthorized",
+			"status": 10005,
+		})
+		return
+	}
+
+	now := time.Now()
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := auth.RevokeUserTokens(tx, principal.UserID); err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", principal.UserID).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		log.Printf("failed to revoke tokens of user %d: %v", principal.UserID, err)
+		c.JSON(consts.StatusInternalServerError, utils.H{
+			"info":   "Failed to revoke tokens",
+			"status": 10003,
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, utils.H{
+		"info":   "success",
+		"status": 10000,
+	})
+}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func postWithToken(h *server.Hertz, url, token string) *protocol.Response {
+	return ut.PerformRequest(h.Engine, consts.MethodPost, url, nil,
+		ut.Header{Key: "Authorization", Value: "Bearer " + token},
+	).Result()
+}
+
+func TestLogoutRevokesTokenAndRefreshFamily(t *testing.T) {
+	h, db := newTestServer(t)
+	createUser(t, db, "bob", "secret123")
+	session := mustLogin(t, h, "bob", "secret123")
+	other := mustLogin(t, h, "bob", "secret123")
+
+	if resp := postWithToken(h, "/user/logout?refresh_token="+session.RefreshToken, session.Token); resp.StatusCode() != consts.StatusOK {
+		t.Fatalf("logout: status %d, body %s", resp.StatusCode(), resp.Body())
+	}
+	if resp := postWithToken(h, "/user/logout", session.Token); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Errorf("revoked access token: status %d, want 401", resp.StatusCode())
+	}
+	if resp := refresh(h, session.RefreshToken); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Errorf("refresh token of logged out session: status %d, want 401", resp.StatusCode())
+	}
+	// 其他会话不受影响
+	mustRefresh(t, h, other.RefreshToken)
+}
+
+func TestLogoutAllRevokesEverySession(t *testing.T) {
+	h, db := newTestServer(t)
+	createUser(t, db, "bob", "secret123")
+	createUser(t, db, "eve", "secret123")
+	first := mustLogin(t, h, "bob", "secret123")
+	second := mustLogin(t, h, "bob", "secret123")
+	eve := mustLogin(t, h, "eve", "secret123")
+
+	if resp := postWithToken(h, "/user/logout-all", first.Token); resp.StatusCode() != consts.StatusOK {
+		t.Fatalf("logout-all: status %d, body %s", resp.StatusCode(), resp.Body())
+	}
+	for name, session := range map[string]TokenData{"first": first, "second": second} {
+		if resp := postWithToken(h, "/user/logout", session.Token); resp.StatusCode() != consts.StatusUnauthorized {
+			t.Errorf("%s access token: status %d, want 401", name, resp.StatusCode())
+		}
+		if resp := refresh(h, session.RefreshToken); resp.StatusCode() != consts.StatusUnauthorized {
+			t.Errorf("%s refresh token: status %d, want 401", name, resp.StatusCode())
+		}
+	}
+
+	// 紧接着重新登录得到的 token 有效，其他用户不受影响
+	fresh := mustLogin(t, h, "bob", "secret123")
+	if resp := postWithToken(h, "/user/logout", fresh.Token); resp.StatusCode() != consts.StatusOK {
+		t.Errorf("token issued right after logout-all: status %d, want 200", resp.StatusCode())
+	}
+	if resp := postWithToken(h, "/user/logout", eve.Token); resp.StatusCode() != consts.StatusOK {
+		t.Errorf("other user's token: status %d, want 200", resp.StatusCode())
+	}
+}
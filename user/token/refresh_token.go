@@ -0,0 +1,104 @@
+package token
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/models"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+var (
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// generateRefreshToken 签发属于 familyID 的刷新 Token，并在数据库中登记
+func generateRefreshToken(tx *gorm.DB, user models.User, familyID string) (string, error) {
+	claims, err := auth.NewClaims(auth.TokenTypeRefresh, principalOf(user), refreshTTL)
+	if err != nil {
+		return "", err
+	}
+
+	record := models.RefreshToken{
+		JTI:       claims.Id,
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return auth.Sign(claims)
+}
+
+// validateRefreshToken 校验刷新 Token 的签名、有效期和类型
+func validateRefreshToken(refreshToken string) (*auth.Claims, error) {
+	claims, err := auth.Parse(refreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return nil, errInvalidRefreshToken
+	}
+	return claims, nil
+}
+
+// rotateRefreshToken 把刷新 Token 标记为已使用，并在同一 family 中签发新的刷新 Token。
+// 已使用过的刷新 Token 再次出现说明它可能已经泄露，此时吊销整个 family。
+func rotateRefreshToken(claims *auth.Claims) (models.User, string, error) {
+	var user models.User
+	var newRefreshToken string
+	var reusedFamilyID string
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		if err := tx.Where("jti = ?", claims.Id).First(&stored).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidRefreshToken
+			}
+			return err
+		}
+		if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+			return errInvalidRefreshToken
+		}
+
+		// 条件更新保证并发请求中只有一个能使用该 token
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reusedFamilyID = stored.FamilyID
+			return errRefreshTokenReused
+		}
+
+		if err := tx.Preload("Roles").First(&user, stored.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidRefreshToken
+			}
+			return err
+		}
+
+		var err error
+		newRefreshToken, err = generateRefreshToken(tx, user, stored.FamilyID)
+		return err
+	})
+
+	// 事务已回滚，吊销操作需要单独提交
+	if errors.Is(err, errRefreshTokenReused) {
+		log.Printf("refresh token %s reused, revoking family %s", claims.Id, reusedFamilyID)
+		if revokeErr := revokeFamily(DB, reusedFamilyID); revokeErr != nil {
+			log.Printf("failed to revoke refresh token family %s: %v", reusedFamilyID, revokeErr)
+		}
+	}
+	return user, newRefreshToken, err
+}
+
+// revokeFamily 吊销同一 family 中尚未吊销的全部刷新 Token
+func revokeFamily(db *gorm.DB, familyID string) error {
+	return db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,76 @@
+package token
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func refresh(h *server.Hertz, refreshToken string) *protocol.Response {
+	return ut.PerformRequest(h.Engine, consts.MethodGet, "/user/token/refresh?refresh_token="+refreshToken, nil).Result()
+}
+
+// mustRefresh 使用刷新 token 换取新的 token
+func mustRefresh(t *testing.T, h *server.Hertz, refreshToken string) TokenData {
+	t.Helper()
+	resp := refresh(h, refreshToken)
+	if resp.StatusCode() != consts.StatusOK {
+		t.Fatalf("refresh: status %d, body %s", resp.StatusCode(), resp.Body())
+	}
+	var out TokenResponse
+	if err := json.Unmarshal(resp.Body(), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.Data
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	h, db := newTestServer(t)
+	createUser(t, db, "bob", "secret123")
+	first := mustLogin(t, h, "bob", "secret123")
+
+	second := mustRefresh(t, h, first.RefreshToken)
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	if second.Token == "" {
+		t.Fatal("refresh returned no access token")
+	}
+	// 轮换后的新 token 可以继续使用
+	mustRefresh(t, h, second.RefreshToken)
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	h, db := newTestServer(t)
+	createUser(t, db, "bob", "secret123")
+	first := mustLogin(t, h, "bob", "secret123")
+	other := mustLogin(t, h, "bob", "secret123")
+	second := mustRefresh(t, h, first.RefreshToken)
+
+	if resp := refresh(h, first.RefreshToken); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Fatalf("reused refresh token: status %d, want 401", resp.StatusCode())
+	}
+	// 复用之后同一 family 中尚未使用的 token 一并失效
+	if resp := refresh(h, second.RefreshToken); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Errorf("refresh token in revoked family: status %d, want 401", resp.StatusCode())
+	}
+	// 另一次登录属于不同的 family，不受影响
+	mustRefresh(t, h, other.RefreshToken)
+}
+
+func TestRefreshRejectsAccessToken(t *testing.T) {
+	h, db := newTestServer(t)
+	createUser(t, db, "bob", "secret123")
+	tokens := mustLogin(t, h, "bob", "secret123")
+
+	if resp := refresh(h, tokens.Token); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Errorf("access token used as refresh token: status %d, want 401", resp.StatusCode())
+	}
+	if resp := refresh(h, ""); resp.StatusCode() != consts.StatusBadRequest {
+		t.Errorf("missing refresh token: status %d, want 400", resp.StatusCode())
+	}
+}
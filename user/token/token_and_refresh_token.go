@@ -1,26 +1,25 @@
-package main
+package token
 
 import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/models"
+	"awesomeProject/passhash"
+	"awesomeProject/product/cart"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
-	"github.com/dgrijalva/jwt-go"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"log"
 	"time"
 )
 
-// User 定义用户结构体
-type User struct {
-	ID       int    `gorm:"primaryKey"`
-	Username string `gorm:"unique;not null"`
-	Password string `gorm:"not null"`
-}
-
 // TokenResponse 定义返回的 token 响应结构体
 type TokenResponse struct {
 	Status int       `json:"status"`
@@ -35,168 +34,178 @@ type TokenData struct {
 }
 
 var DB *gorm.DB
-var jwtKey = []byte("your_secret_key")
 
-// InitDB 初始化数据库连接
-func InitDB() error {
-	dsn := "root:123456@tcp(127.0.0.1:3306)/MySQL?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to connect database: %w", err)
-	}
+// 访问 token 和刷新 token 的有效期
+var accessTTL, refreshTTL time.Duration
+
+// InitDB 注入共享的数据库连接并读取 token 有效期
+func InitDB(db *gorm.DB, cfg *config.Config) error {
+	accessTTL = cfg.JWT.AccessTTL
+	refreshTTL = cfg.JWT.RefreshTTL
 	DB = db
 	return nil
 }
 
-// generateToken 生成 JWT Token
-func generateToken(username string) (string, error) {
-	expirationTime := time.Now().Add(2 * time.Hour)
-	claims := &jwt.StandardClaims{
-		ExpiresAt: expirationTime.Unix(),
-		Subject:   username,
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+// rehashPassword 使用当前的哈希参数重新保存用户密码
+func rehashPassword(user *models.User, plain string) {
+	hashed, err := passhash.Hash(plain)
 	if err != nil {
-		return "", err
+		log.Printf("failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+	if err := DB.Model(user).Update("password", hashed).Error; err != nil {
+		log.Printf("failed to save rehashed password for user %d: %v", user.ID, err)
 	}
-	return tokenString, nil
 }
 
-// generateRefreshToken 生成刷新 Token
-func generateRefreshToken(username string) (string, error) {
-	expirationTime := time.Now().Add(2 * time.Hour)
-	claims := &jwt.StandardClaims{
-		ExpiresAt: expirationTime.Unix(),
-		Subject:   username,
+// principalOf 把用户转换为写入 token 的 Principal，user 需要预加载 Roles
+func principalOf(user models.User) auth.Principal {
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
 	}
+	return auth.Principal{UserID: user.ID, Username: user.Username, Roles: roles, TokenVersion: user.TokenVersion}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
-	if err != nil {
-		return "", err
+// newFamilyID 生成新的刷新 token family ID
+func newFamilyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate family id: %w", err)
 	}
-	return tokenString, nil
+	return hex.EncodeToString(b), nil
 }
 
-// validateRefreshToken 验证刷新 Token
-func validateRefreshToken(refreshToken string) (string, error) {
-	claims := &jwt.StandardClaims{}
-
-	tkn, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
-
+// generateToken 生成访问 Token
+func generateToken(user models.User) (string, error) {
+	claims, err := auth.NewClaims(auth.TokenTypeAccess, principalOf(user), accessTTL)
 	if err != nil {
 		return "", err
 	}
-
-	if !tkn.Valid {
-		return "", fmt.Errorf("invalid refresh token")
-	}
-
-	return claims.Subject, nil
+	return auth.Sign(claims)
 }
 
-func main() {
-	err := InitDB()
-	if err != nil {
-		log.Fatalf("failed to initialize database: %v", err)
+// GetToken 用户登录，签发 token 和刷新 token
+func GetToken(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
 	}
 
-	h := server.New(server.WithHostPorts("127.0.0.1:8002"))
+	if err := c.BindAndValidate(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{"code": 400, "message": "Invalid request"})
+		return
+	}
 
-	// 注册获取 token 的路由（用户登录）
-	h.GET("/user/token", func(ctx context.Context, c *app.RequestContext) {
-		var req struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
+	// 验证用户名和密码
+	var user models.User
+	result := DB.Preload("Roles").Where("username = ?", req.Username).First(&user)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			passhash.VerifyMissing(req.Password)
+			c.JSON(consts.StatusUnauthorized, utils.H{"code": 401, "message": "Invalid username or password"})
+		} else {
+			c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Database error"})
 		}
+		return
+	}
+	ok, needsRehash := passhash.Verify(user.Password, req.Password)
+	if !ok {
+		c.JSON(consts.StatusUnauthorized, utils.H{"code": 401, "message": "Invalid username or password"})
+		return
+	}
+	// 旧的明文或低 cost 密码在登录成功后升级为新哈希，失败不影响本次登录
+	if needsRehash {
+		rehashPassword(&user, req.Password)
+	}
 
-		if err := c.BindAndValidate(&req); err != nil {
-			c.JSON(consts.StatusBadRequest, utils.H{"code": 400, "message": "Invalid request"})
-			return
-		}
+	// 生成 Token 和刷新 Token
+	token, err := generateToken(user)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate token"})
+		return
+	}
+	familyID, err := newFamilyID()
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate refresh token"})
+		return
+	}
+	refreshToken, err := generateRefreshToken(DB, user, familyID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate refresh token"})
+		return
+	}
 
-		// 验证用户名和密码
-		var user User
-		result := DB.Where("username = ? AND password = ?", req.Username, req.Password).First(&user)
-		if result.Error != nil {
-			if result.Error == gorm.ErrRecordNotFound {
-				c.JSON(consts.StatusUnauthorized, utils.H{"code": 401, "message": "Invalid username or password"})
-			} else {
-				c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Database error"})
-			}
-			return
-		}
+	// 游客购物车合并失败不影响本次登录，游客 cookie 保留，下次登录时重试
+	if err := cart.MergeGuestCart(c, user.ID); err != nil {
+		log.Printf("%v", err)
+	}
 
-		// 生成 Token 和刷新 Token
-		token, err := generateToken(req.Username)
-		if err != nil {
-			c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate token"})
-			return
-		}
-		refreshToken, err := generateRefreshToken(req.Username)
-		if err != nil {
-			c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate refresh token"})
-			return
-		}
+	// 返回 token
+	response := TokenResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: TokenData{
+			RefreshToken: refreshToken,
+			Token:        token,
+		},
+	}
 
-		// 返回 token
-		response := TokenResponse{
-			Status: 10000,
-			Info:   "success",
-			Data: TokenData{
-				RefreshToken: refreshToken,
-				Token:        token,
-			},
-		}
+	c.JSON(consts.StatusOK, response)
+}
 
-		c.JSON(consts.StatusOK, response)
-	})
+// RefreshToken 使用刷新 token 换取新的 token
+func RefreshToken(ctx context.Context, c *app.RequestContext) {
+	refreshToken := c.Query("refresh_token")
 
-	// 注册刷新 token 的路由
-	h.GET("/user/token/refresh", func(ctx context.Context, c *app.RequestContext) {
-		refreshToken := c.Query("refresh_token")
+	if refreshToken == "" {
+		c.JSON(
+			consts.StatusBadRequest,
+			utils.H{"code": 400, "message": "refresh_token is required"})
+		return
+	}
 
-		if refreshToken == "" {
-			c.JSON(
-				consts.StatusBadRequest,
-				utils.H{"code": 400, "message": "refresh_token is required"})
-			return
-		}
+	// 验证刷新 Token
+	claims, err := validateRefreshToken(refreshToken)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"code": 401, "message": "Invalid refresh token"})
+		return
+	}
 
-		// 验证刷新 Token
-		username, err := validateRefreshToken(refreshToken)
-		if err != nil {
+	// 轮换刷新 Token，旧的刷新 Token 随即失效
+	user, newRefreshToken, err := rotateRefreshToken(claims)
+	if err != nil {
+		if errors.Is(err, errInvalidRefreshToken) || errors.Is(err, errRefreshTokenReused) {
 			c.JSON(consts.StatusUnauthorized, utils.H{"code": 401, "message": "Invalid refresh token"})
-			return
-		}
-
-		// 生成新的 Token 和刷新 Token
-		newToken, err := generateToken(username)
-		if err != nil {
-			c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate new token"})
-			return
-		}
-		newRefreshToken, err := generateRefreshToken(username)
-		if err != nil {
+		} else {
 			c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate new refresh token"})
-			return
 		}
+		return
+	}
 
-		// 返回新的 token
-		response := TokenResponse{
-			Status: 10000,
-			Info:   "success",
-			Data: TokenData{
-				RefreshToken: newRefreshToken,
-				Token:        newToken,
-			},
-		}
-		c.JSON(consts.StatusOK, response)
-	})
+	// 生成新的 Token
+	newToken, err := generateToken(user)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"code": 500, "message": "Failed to generate new token"})
+		return
+	}
+
+	// 返回新的 token
+	response := TokenResponse{
+		Status: 10000,
+		Info:   "success",
+		Data: TokenData{
+			RefreshToken: newRefreshToken,
+			Token:        newToken,
+		},
+	}
+	c.JSON(consts.StatusOK, response)
+}
 
-	h.Run()
+// RegisterRoutes 注册获取、刷新和吊销 token 的路由
+func RegisterRoutes(r *server.Hertz) {
+	r.GET("/user/token", GetToken)
+	r.GET("/user/token/refresh", RefreshToken)
+	r.POST("/user/logout", auth.JWTAuthorization(), Logout)
+	r.POST("/user/logout-all", auth.JWTAuthorization(), LogoutAll)
 }
@@ -0,0 +1,120 @@
+package token
+
+import (
+	"awesomeProject/auth"
+	"awesomeProject/config"
+	"awesomeProject/migration"
+	"awesomeProject/models"
+	"awesomeProject/passhash"
+	"awesomeProject/product/cart"
+	"awesomeProject/storage"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
+)
+
+// newTestServer 返回注册了 token 路由的服务和执行过全部迁移的内存数据库
+func newTestServer(t *testing.T) (*server.Hertz, *gorm.DB) {
+	t.Helper()
+	db, err := storage.OpenInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	if err := auth.InitDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cart.InitDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	h := server.New()
+	RegisterRoutes(h)
+	return h, db
+}
+
+// createUser 直接写入用户，password 原样保存，可以是明文或哈希
+func createUser(t *testing.T, db *gorm.DB, username, password string) models.User {
+	t.Helper()
+	user := models.User{Username: username, Password: password}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func login(h *server.Hertz, username, password string) *protocol.Response {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	return ut.PerformRequest(h.Engine, consts.MethodGet, "/user/token",
+		&ut.Body{Body: bytes.NewReader(body), Len: len(body)},
+		ut.Header{Key: consts.HeaderContentType, Value: consts.MIMEApplicationJSON},
+		ut.Header{Key: consts.HeaderContentLength, Value: strconv.Itoa(len(body))},
+	).Result()
+}
+
+// mustLogin 登录并返回签发的 token
+func mustLogin(t *testing.T, h *server.Hertz, username, password string) TokenData {
+	t.Helper()
+	resp := login(h, username, password)
+	if resp.StatusCode() != consts.StatusOK {
+		t.Fatalf("login %s: status %d, body %s", username, resp.StatusCode(), resp.Body())
+	}
+	var out TokenResponse
+	if err := json.Unmarshal(resp.Body(), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.Data
+}
+
+func storedPassword(t *testing.T, db *gorm.DB, userID uint) string {
+	t.Helper()
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.Password
+}
+
+func TestLoginUpgradesPlaintextPassword(t *testing.T) {
+	h, db := newTestServer(t)
+	user := createUser(t, db, "bob", "secret123")
+
+	mustLogin(t, h, "bob", "secret123")
+
+	stored := storedPassword(t, db, user.ID)
+	if stored == "secret123" {
+		t.Fatal("plaintext password was not upgraded after login")
+	}
+	if ok, needsRehash := passhash.Verify(stored, "secret123"); !ok || needsRehash {
+		t.Errorf("Verify(upgraded, correct) = %v, %v, want true, false", ok, needsRehash)
+	}
+	// 升级后仍然可以用原密码登录
+	mustLogin(t, h, "bob", "secret123")
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	h, db := newTestServer(t)
+	user := createUser(t, db, "bob", "secret123")
+
+	if resp := login(h, "bob", "wrong"); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Errorf("wrong password: status %d, want 401", resp.StatusCode())
+	}
+	if resp := login(h, "nobody", "secret123"); resp.StatusCode() != consts.StatusUnauthorized {
+		t.Errorf("unknown user: status %d, want 401", resp.StatusCode())
+	}
+	if stored := storedPassword(t, db, user.ID); stored != "secret123" {
+		t.Errorf("password changed after a failed login: %q", stored)
+	}
+}